@@ -0,0 +1,150 @@
+package tokenizer
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Abbreviations is a set of words that keep their trailing period.
+// Abbreviations are matched ignoring case and without the final period,
+// so "Dr", "dr.", and "DR" all denote the same entry.
+type Abbreviations struct {
+	words map[string]bool // the lowercased abbreviations, without the final period
+}
+
+// the built-in abbreviations, by ISO 639-1 language code;
+// single-letter initials ("J.", "U.S.", "e.g.") are recognized anyway,
+// and words that also end sentences ("no", "art") are left out
+var builtinAbbreviations = map[string][]string{
+	"de": {
+		"abb", "abs", "abt", "bd", "bspw", "bzgl", "bzw", "ca", "chr", "dgl",
+		"dipl", "dr", "ebd", "evtl", "fa", "ff", "frl", "gebr", "ggf", "hr",
+		"hrsg", "inkl", "insb", "jh", "jr", "kap", "lt", "mio", "mrd", "nr",
+		"prof", "rd", "sog", "spez", "std", "str", "tel", "usw", "vgl", "zt",
+		"ziff", "zzgl",
+	},
+	"en": {
+		"approx", "apr", "assn", "aug", "ave", "blvd", "capt", "cf", "co",
+		"col", "corp", "dec", "dept", "dr", "est", "etc", "feb", "fig",
+		"figs", "gen", "gov", "inc", "jan", "jr", "jul", "jun", "lt", "ltd",
+		"mar", "mr", "mrs", "ms", "mt", "nov", "oct", "ph.d", "prof", "rep",
+		"rev", "sen", "sep", "sept", "sgt", "sr", "st", "vol", "vs",
+	},
+	"es": {
+		"admón", "apdo", "av", "avda", "cía", "dña", "dra", "etc", "excmo",
+		"ilmo", "lic", "núm", "pág", "págs", "prof", "sr", "sra", "srta",
+		"ud", "uds", "vd", "vds",
+	},
+	"fr": {
+		"av", "bd", "cf", "chap", "dr", "éd", "etc", "ex", "fig", "janv",
+		"mlle", "mme", "mgr", "pp", "prof", "sept", "st", "ste", "vol",
+	},
+	"nl": {
+		"afb", "bijv", "blz", "bv", "ca", "dhr", "dr", "drs", "enz", "etc",
+		"evt", "ir", "jl", "mevr", "mr", "nl", "nr", "prof", "resp", "vgl",
+		"zgn",
+	},
+}
+
+// NewAbbreviations creates a set of abbreviations from a list of words
+// (with or without their final period).
+func NewAbbreviations(words []string) *Abbreviations {
+	a := &Abbreviations{words: make(map[string]bool, len(words))}
+
+	for _, w := range words {
+		a.Add(w)
+	}
+
+	return a
+}
+
+// BuiltinAbbreviations returns the built-in abbreviations of a language,
+// given as ISO 639-1 code ("de", "en", "es", "fr", or "nl"),
+// or nil if there are none for that language.
+func BuiltinAbbreviations(language string) []string {
+	return builtinAbbreviations[strings.ToLower(language)]
+}
+
+// AbbreviationLanguages returns the codes of all languages
+// with built-in abbreviations, sorted.
+func AbbreviationLanguages() []string {
+	languages := make([]string, 0, len(builtinAbbreviations))
+
+	for language := range builtinAbbreviations {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+	return languages
+}
+
+// ReadAbbreviations loads a set of abbreviations with one word per line;
+// like ReadDictionary, only the first field of each line is used.
+func ReadAbbreviations(r io.Reader) (*Abbreviations, error) {
+	a := NewAbbreviations(nil)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			a.Add(fields[0])
+		}
+	}
+
+	return a, scanner.Err()
+}
+
+// Add a word to the set of abbreviations.
+func (a *Abbreviations) Add(word string) {
+	if word = strings.TrimSuffix(word, "."); word != "" {
+		a.words[strings.ToLower(word)] = true
+	}
+}
+
+// Merge adds all abbreviations of another set to this one.
+func (a *Abbreviations) Merge(other *Abbreviations) {
+	for word := range other.words {
+		a.words[word] = true
+	}
+}
+
+// Contains is true if the word (with or without its final period)
+// is a known abbreviation.
+func (a *Abbreviations) Contains(word string) bool {
+	return a.words[strings.ToLower(strings.TrimSuffix(word, "."))]
+}
+
+// the abbreviations used if the lexer has none in its Resources
+var defaultAbbreviations = NewAbbreviations(BuiltinAbbreviations("en"))
+
+// true if the word consists of single letters separated by periods,
+// and is either a single uppercase letter ("J") or has several ("U.S", "e.g")
+func isInitials(word string) bool {
+	parts := strings.Split(word, ".")
+
+	for _, part := range parts {
+		r, w := utf8.DecodeRuneInString(part)
+
+		if w != len(part) || !unicode.IsLetter(r) {
+			return false
+		} else if len(parts) == 1 && !unicode.IsUpper(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// true if the word (without its period) is a known abbreviation or initials
+func (l *lexer) isAbbreviation(word string) bool {
+	abbreviations := l.resources.Abbreviations
+
+	if abbreviations == nil {
+		abbreviations = defaultAbbreviations
+	}
+
+	return isInitials(word) || abbreviations.Contains(word)
+}
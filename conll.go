@@ -0,0 +1,176 @@
+package tokenizer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A Sentence is an input text and its tokens,
+// as written to and read from CoNLL-U files.
+//
+// Each token is written as a line with the ten CoNLL-U columns:
+// its index (from 1), its value (FORM), its class name (XPOS),
+// and its byte offsets in the text (as "TokenRange=start:end" in MISC,
+// with "SpaceAfter=No" if the next token follows without a gap);
+// all other columns are "_".
+// The text precedes the tokens in a "# text = ..." comment line,
+// and a blank line follows them.
+// Backslashes, tabs, and linebreaks in texts and values are escaped
+// ("\\", "\t", "\n", "\r"), as CoNLL-U lines cannot hold them.
+// EndTokens are not written.
+type Sentence struct {
+	Text   string  // the input text
+	Tokens []Token // the tokens of the text
+}
+
+// the CoNLL-U escapes of backslashes, tabs, and linebreaks
+var conllEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// the CoNLL-U unescapes of backslashes, tabs, and linebreaks
+var conllUnescaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\r`, "\r")
+
+// WriteTo writes the sentence in CoNLL-U format.
+func (s *Sentence) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	var tokens []Token
+
+	for _, token := range s.Tokens {
+		if !token.IsEnd() {
+			tokens = append(tokens, token)
+		}
+	}
+
+	count, err := fmt.Fprintf(w, "# text = %s\n", conllEscaper.Replace(s.Text))
+	n += int64(count)
+
+	for i := 0; err == nil && i < len(tokens); i++ {
+		misc := fmt.Sprintf("TokenRange=%d:%d", tokens[i].Start, tokens[i].End)
+
+		if i+1 < len(tokens) && tokens[i+1].Start == tokens[i].End {
+			misc = "SpaceAfter=No|" + misc
+		}
+
+		count, err = fmt.Fprintf(w, "%d\t%s\t_\t_\t%s\t_\t_\t_\t_\t%s\n",
+			i+1, conllEscaper.Replace(tokens[i].Value), tokens[i].ClassName(), misc)
+		n += int64(count)
+	}
+
+	if err == nil {
+		count, err = io.WriteString(w, "\n")
+		n += int64(count)
+	}
+
+	return n, err
+}
+
+// A CoNLLReader reads the sentences of a CoNLL-U file,
+// as written by Sentence.WriteTo.
+type CoNLLReader struct {
+	scanner *bufio.Scanner
+	line    int // the number of the last line read
+}
+
+// NewCoNLLReader creates a reader of the CoNLL-U sentences in r.
+func NewCoNLLReader(r io.Reader) *CoNLLReader {
+	return &CoNLLReader{scanner: bufio.NewScanner(r)}
+}
+
+// Read returns the next sentence, or io.EOF after the last one.
+//
+// The tokens' Raw values are taken from the text at their offsets
+// (if the sentence has a text comment), while their Shape, Features,
+// and Script are not set.
+// Other comments and multiword token or empty node lines are skipped.
+func (c *CoNLLReader) Read() (*Sentence, error) {
+	var sentence *Sentence
+
+	for c.scanner.Scan() {
+		c.line++
+		line := c.scanner.Text()
+
+		if line == "" {
+			if sentence != nil {
+				return sentence, nil
+			}
+
+			continue
+		} else if sentence == nil {
+			sentence = &Sentence{}
+		}
+
+		if strings.HasPrefix(line, "#") {
+			if text := strings.TrimPrefix(line, "# text = "); text != line {
+				sentence.Text = conllUnescaper.Replace(text)
+			}
+		} else if token, ok, err := c.parseToken(line); err != nil {
+			return nil, err
+		} else if ok {
+			if token.Start <= token.End && token.End <= len(sentence.Text) {
+				token.Raw = sentence.Text[token.Start:token.End]
+			}
+
+			sentence.Tokens = append(sentence.Tokens, token)
+		}
+	}
+
+	if err := c.scanner.Err(); err != nil {
+		return nil, err
+	} else if sentence != nil {
+		return sentence, nil
+	}
+
+	return nil, io.EOF
+}
+
+// parseToken parses a token line; returns false
+// if the line is a multiword token or empty node
+func (c *CoNLLReader) parseToken(line string) (Token, bool, error) {
+	var token Token
+	columns := strings.Split(line, "\t")
+
+	if len(columns) != 10 {
+		return token, false, fmt.Errorf("line %d: expected 10 columns, found %d", c.line, len(columns))
+	} else if strings.ContainsAny(columns[0], "-.") {
+		return token, false, nil
+	}
+
+	class, ok := classOf(columns[4])
+
+	if !ok {
+		return token, false, fmt.Errorf("line %d: unknown token class %q", c.line, columns[4])
+	}
+
+	token.Class = class
+	token.Value = conllUnescaper.Replace(columns[1])
+
+	for _, field := range strings.Split(columns[9], "|") {
+		if tokenRange := strings.TrimPrefix(field, "TokenRange="); tokenRange != field {
+			start, end, _ := strings.Cut(tokenRange, ":")
+			var err error
+
+			if token.Start, err = strconv.Atoi(start); err == nil {
+				token.End, err = strconv.Atoi(end)
+			}
+
+			if err != nil {
+				return token, false, fmt.Errorf("line %d: invalid TokenRange %q", c.line, tokenRange)
+			}
+		}
+	}
+
+	return token, true, nil
+}
+
+// classOf returns the token class with the given name
+func classOf(name string) (TokenClass, bool) {
+	for class, n := range className {
+		if n == name {
+			return TokenClass(class), true
+		}
+	}
+
+	return EndToken, false
+}
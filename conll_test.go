@@ -0,0 +1,94 @@
+package tokenizer
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func lexSentences(texts ...string) []*Sentence {
+	in := make(chan string, len(texts))
+	sentences := []*Sentence{{}}
+
+	for _, text := range texts {
+		in <- text
+	}
+
+	close(in)
+
+	for token := range Lex(in, 100, Spaces|Entities) {
+		last := sentences[len(sentences)-1]
+
+		if token.IsEnd() {
+			last.Text = texts[len(sentences)-1]
+			sentences = append(sentences, &Sentence{})
+		} else {
+			last.Tokens = append(last.Tokens, token)
+		}
+	}
+
+	return sentences[:len(sentences)-1]
+}
+
+func TestSentenceWriteTo(t *testing.T) {
+	var buffer bytes.Buffer
+	expected := "# text = Hi,\\tyou!\n" +
+		"1\tHi\t_\t_\tWord\t_\t_\t_\t_\tSpaceAfter=No|TokenRange=0:2\n" +
+		"2\t,\t_\t_\tSymbol\t_\t_\t_\t_\tSpaceAfter=No|TokenRange=2:3\n" +
+		"3\t\\t\t_\t_\tSpace\t_\t_\t_\t_\tSpaceAfter=No|TokenRange=3:4\n" +
+		"4\tyou\t_\t_\tWord\t_\t_\t_\t_\tSpaceAfter=No|TokenRange=4:7\n" +
+		"5\t!\t_\t_\tSymbol\t_\t_\t_\t_\tTokenRange=7:8\n" +
+		"\n"
+
+	sentence := lexSentences("Hi,\tyou!")[0]
+	n, err := sentence.WriteTo(&buffer)
+
+	if err != nil {
+		t.Fatal(err)
+	} else if buffer.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buffer.String())
+	} else if n != int64(buffer.Len()) {
+		t.Errorf("expected %d bytes written, got %d", buffer.Len(), n)
+	}
+}
+
+func TestCoNLLRoundTrip(t *testing.T) {
+	var buffer bytes.Buffer
+	sentences := lexSentences("Fish &amp; chips", "", `a\b "quoted"`)
+
+	for _, sentence := range sentences {
+		if _, err := sentence.WriteTo(&buffer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader := NewCoNLLReader(&buffer)
+
+	for i, expected := range sentences {
+		sentence, err := reader.Read()
+
+		if err != nil {
+			t.Fatalf("sentence %d: %s", i, err)
+		} else if !reflect.DeepEqual(sentence, expected) {
+			t.Errorf("sentence %d: expected %v, got %v", i, expected, sentence)
+		}
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestCoNLLReaderErrors(t *testing.T) {
+	for description, input := range map[string]string{
+		"columns": "1\tword\t_\tWord\n",
+		"class":   "1\tword\t_\t_\tNoun\t_\t_\t_\t_\t_\n",
+		"range":   "1\tword\t_\t_\tWord\t_\t_\t_\t_\tTokenRange=0-4\n",
+	} {
+		if _, err := NewCoNLLReader(strings.NewReader(input)).Read(); err == nil {
+			t.Errorf("%s: expected an error", description)
+		}
+	}
+}
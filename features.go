@@ -0,0 +1,203 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// orthographic features of a token
+type Feature int
+
+// all possible orthographic features (combined by or-ing)
+const (
+	AllCapsFeature   Feature = 1 << iota // all letters are uppercase
+	TitleCaseFeature                     // only the first of several letters is uppercase
+	LowerCaseFeature                     // all letters are lowercase
+	MixedCaseFeature                     // any other mix of upper- and lowercase letters
+	DigitFeature                         // has a digit
+	HyphenFeature                        // has a hyphen or dash
+	GreekFeature                         // has a Greek letter (possibly before its expansion)
+)
+
+var featureName = []string{
+	"AllCaps",
+	"TitleCase",
+	"LowerCase",
+	"MixedCase",
+	"Digit",
+	"Hyphen",
+	"Greek",
+}
+
+// the maximum number of repeated shape runes ("Xxxxxxx" -> "Xxxx")
+const maxShapeRepeat = 4
+
+// the feature names, joined by commas ("-" if there are none)
+func (f Feature) String() string {
+	var names []string
+
+	for i, name := range featureName {
+		if f&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "-"
+	}
+
+	return strings.Join(names, ",")
+}
+
+// true if all the given features are set
+func (f Feature) Has(features Feature) bool {
+	return f&features == features
+}
+
+// Shape returns the word shape and the orthographic features of a value.
+//
+// In the shape, uppercase letters are replaced with "X",
+// other letters with "x", digits with "d", while any other rune is kept;
+// runs of the same shape rune are cut after four repetitions:
+//
+//	"Appleseed" -> "Xxxxx", "12-34" -> "dd-dd", "IL22" -> "XXdd"
+//
+// The lexer's Shapes option annotates tokens with the same shape and features,
+// but computes them from the runes as it scans them.
+func Shape(value string) (string, Feature) {
+	var s shaper
+	s.fill(value, len(value))
+	return s.result()
+}
+
+// a shaper computes the shape and features of a token rune by rune,
+// keeping the state after each rune so the lexer can back up
+type shaper struct {
+	start  int          // the buffer position of the token
+	shape  []byte       // the shape so far
+	states []shapeState // the state after each rune
+}
+
+// the state of a shaper after a rune
+type shapeState struct {
+	end          int     // the buffer position after the rune
+	length       int     // the length of the shape
+	last         rune    // the last shape rune
+	repeat       int     // the repetitions of the last shape rune
+	upper, lower int     // the number of upper- and lowercase letters
+	title        bool    // true if the first rune is uppercase
+	features     Feature // the features other than the case features
+}
+
+// reset starts the shape of a token at the buffer position
+func (s *shaper) reset(start int) {
+	s.start = start
+	s.shape = s.shape[:0]
+	s.states = s.states[:0]
+}
+
+// end returns the buffer position after the last rune shaped
+func (s *shaper) end() int {
+	if len(s.states) == 0 {
+		return s.start
+	}
+
+	return s.states[len(s.states)-1].end
+}
+
+// truncate drops the runes shaped after the buffer position
+func (s *shaper) truncate(pos int) {
+	n := len(s.states)
+
+	for n > 0 && s.states[n-1].end > pos {
+		n--
+	}
+
+	s.states = s.states[:n]
+
+	if n == 0 {
+		s.shape = s.shape[:0]
+	} else {
+		s.shape = s.shape[:s.states[n-1].length]
+	}
+}
+
+// fill shapes the runes of the buffer that were not scanned up to the position
+func (s *shaper) fill(buffer string, pos int) {
+	for end := s.end(); end < pos; {
+		r, w := utf8.DecodeRuneInString(buffer[end:])
+		end += w
+		s.add(r, end)
+	}
+}
+
+// add shapes the next rune, ending at the buffer position
+func (s *shaper) add(r rune, end int) {
+	var state shapeState
+	c := r
+
+	if len(s.states) > 0 {
+		state = s.states[len(s.states)-1]
+	}
+
+	switch {
+	case unicode.IsUpper(r):
+		c = 'X'
+		state.upper++
+		state.title = len(s.states) == 0
+	case unicode.IsLetter(r):
+		c = 'x'
+		if unicode.IsLower(r) {
+			state.lower++
+		}
+	case unicode.IsDigit(r):
+		c = 'd'
+		state.features |= DigitFeature
+	case r == '-' || strings.ContainsRune(hyphens, r):
+		state.features |= HyphenFeature
+	}
+
+	if unicode.Is(unicode.Greek, r) {
+		state.features |= GreekFeature
+	}
+
+	if c == state.last && state.repeat > 0 {
+		state.repeat++
+	} else {
+		state.last = c
+		state.repeat = 1
+	}
+
+	if state.repeat <= maxShapeRepeat {
+		s.shape = utf8.AppendRune(s.shape, c)
+	}
+
+	state.end = end
+	state.length = len(s.shape)
+	s.states = append(s.states, state)
+}
+
+// result returns the shape and the features of the runes shaped
+func (s *shaper) result() (string, Feature) {
+	var state shapeState
+
+	if len(s.states) > 0 {
+		state = s.states[len(s.states)-1]
+	}
+
+	features := state.features
+
+	switch {
+	case state.upper > 0 && state.lower == 0:
+		features |= AllCapsFeature
+	case state.upper == 0 && state.lower > 0:
+		features |= LowerCaseFeature
+	case state.upper == 1 && state.title:
+		features |= TitleCaseFeature
+	case state.upper > 0:
+		features |= MixedCaseFeature
+	}
+
+	return string(s.shape), features
+}
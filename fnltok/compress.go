@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	bzip2w "github.com/dsnet/compress/bzip2"
+	"github.com/golang/glog"
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+	"github.com/ulikunitz/xz"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// the magic bytes of the compression formats, by file extension
+var compressions = map[string][]byte{
+	".gz":  {0x1f, 0x8b},
+	".bz2": []byte("BZh"),
+	".xz":  {0xfd, '7', 'z', 'X', 'Z', 0x00},
+	".zst": {0x28, 0xb5, 0x2f, 0xfd},
+}
+
+// compressionOf returns the extension of the file's compression format,
+// known from its extension or its first bytes ("" if uncompressed)
+func compressionOf(path string, head []byte) string {
+	if _, ok := compressions[filepath.Ext(path)]; ok {
+		return filepath.Ext(path)
+	}
+
+	for extension, magic := range compressions {
+		if bytes.HasPrefix(head, magic) {
+			return extension
+		}
+	}
+
+	return ""
+}
+
+// a stream, with the things to close when done (in order)
+type stream struct {
+	io.Reader
+	io.Writer
+	closers []func() error
+}
+
+// Close closes all parts of the stream, returning the first error
+func (s *stream) Close() error {
+	var first error
+
+	for _, closer := range s.closers {
+		if err := closer(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// openInput opens a file ("-" for STDIN), decompressing it transparently
+// (gzip in parallel)
+func openInput(path string) io.ReadCloser {
+	file := os.Stdin
+	s := &stream{}
+
+	if path != "-" {
+		var err error
+
+		if file, err = os.Open(path); err != nil {
+			glog.Fatalf("reading %q failed: %s\n", path, err)
+		}
+
+		s.closers = append(s.closers, file.Close)
+	}
+
+	buffer := bufio.NewReader(file)
+	// (errors reading the head will surface again when reading)
+	head, _ := buffer.Peek(6)
+	var err error
+
+	switch compressionOf(path, head) {
+	case ".gz":
+		var reader *pgzip.Reader
+		reader, err = pgzip.NewReader(buffer)
+
+		if err == nil {
+			s.Reader = reader
+			s.closers = append([]func() error{reader.Close}, s.closers...)
+		}
+	case ".bz2":
+		s.Reader = bzip2.NewReader(buffer)
+	case ".xz":
+		s.Reader, err = xz.NewReader(buffer)
+	case ".zst":
+		var decoder *zstd.Decoder
+		decoder, err = zstd.NewReader(buffer)
+
+		if err == nil {
+			s.Reader = decoder
+			s.closers = append([]func() error{func() error { decoder.Close(); return nil }}, s.closers...)
+		}
+	default:
+		s.Reader = buffer
+	}
+
+	if err != nil {
+		glog.Fatalf("decompressing %q failed: %s\n", path, err)
+	}
+
+	return s
+}
+
+// openOutput creates a (buffered) file,
+// compressing it as its extension says
+func openOutput(path string) io.WriteCloser {
+	file, err := os.Create(path)
+
+	if err != nil {
+		glog.Fatalf("creating %q failed: %s\n", path, err)
+	}
+
+	s := &stream{closers: []func() error{file.Close}}
+	var compressor io.WriteCloser
+
+	switch compressionOf(path, nil) {
+	case ".gz":
+		compressor = pgzip.NewWriter(file)
+	case ".bz2":
+		compressor, err = bzip2w.NewWriter(file, nil)
+	case ".xz":
+		compressor, err = xz.NewWriter(file)
+	case ".zst":
+		compressor, err = zstd.NewWriter(file)
+	}
+
+	if err != nil {
+		glog.Fatalf("compressing %q failed: %s\n", path, err)
+	} else if compressor != nil {
+		s.closers = append([]func() error{compressor.Close}, s.closers...)
+		s.Writer = compressor
+	} else {
+		s.Writer = file
+	}
+
+	buffer := bufio.NewWriter(s.Writer)
+	s.Writer = buffer
+	s.closers = append([]func() error{buffer.Flush}, s.closers...)
+	return s
+}
+
+// closeStream closes an input or output stream, failing on errors
+func closeStream(path string, s io.Closer) {
+	if err := s.Close(); err != nil {
+		glog.Fatalf("closing %q failed: %s\n", path, err)
+	}
+}
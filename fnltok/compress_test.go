@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// read a file back, decompressing it
+func readBack(t *testing.T, path string) string {
+	input := openInput(path)
+	defer closeStream(path, input)
+	content, err := io.ReadAll(input)
+
+	if err != nil {
+		t.Fatalf("reading %q failed: %s", path, err)
+	}
+
+	return string(content)
+}
+
+func TestCompressionOf(t *testing.T) {
+	for _, test := range []struct {
+		path     string
+		head     []byte
+		expected string
+	}{
+		{"a.txt", []byte("text"), ""},
+		{"a.txt", nil, ""},
+		{"a.gz", nil, ".gz"},
+		{"a.txt.xz", []byte("text"), ".xz"},
+		{"-", []byte("BZh9"), ".bz2"},
+		{"a.txt", []byte{0x1f, 0x8b, 0x08}, ".gz"},
+		{"a.gz", []byte{0x28, 0xb5, 0x2f, 0xfd}, ".gz"},
+		{"a", []byte{0x28, 0xb5, 0x2f}, ""},
+	} {
+		if result := compressionOf(test.path, test.head); result != test.expected {
+			t.Errorf("%s %q: expected %q, got %q", test.path, test.head, test.expected, result)
+		}
+	}
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	text := strings.Repeat("Hello, Wörld!\n", 1000)
+
+	for extension, magic := range compressions {
+		path := filepath.Join(dir, "text"+extension)
+		output := openOutput(path)
+
+		if _, err := io.WriteString(output, text); err != nil {
+			t.Fatalf("writing %q failed: %s", path, err)
+		}
+
+		closeStream(path, output)
+		content, err := os.ReadFile(path)
+
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.HasPrefix(content, magic) || len(content) >= len(text) {
+			t.Errorf("%s: expected %d compressed bytes starting with %x, got %d bytes starting with %x",
+				extension, len(text), magic, len(content), content[:len(magic)])
+		}
+
+		if result := readBack(t, path); result != text {
+			t.Errorf("%s: expected %d bytes back, got %d", extension, len(text), len(result))
+		}
+
+		// (with the wrong extension, detected from its magic bytes)
+		renamed := filepath.Join(dir, "text"+extension+".txt")
+
+		if err := os.Rename(path, renamed); err != nil {
+			t.Fatal(err)
+		} else if result := readBack(t, renamed); result != text {
+			t.Errorf("%s as .txt: expected %d bytes back, got %d", extension, len(text), len(result))
+		}
+	}
+
+	plain := filepath.Join(dir, "text.txt")
+	output := openOutput(plain)
+	io.WriteString(output, text)
+	closeStream(plain, output)
+
+	if result := readBack(t, plain); result != text {
+		t.Errorf("plain: expected %d bytes back, got %d", len(text), len(result))
+	}
+}
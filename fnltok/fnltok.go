@@ -1,62 +1,160 @@
 /*
-A high-throughput, line-based command-line interface for the tokenizer that writes the tokens to <STDOUT>.
+A high-throughput, line-based command-line interface for the tokenizer that writes the tokens to <STDOUT> (or the -o file).
 This script is about 100 times faster than an equivalent Perl tokenizer using regular expressions for the same task.
 It can tokenize input based on lines and/or tab-separated values (while preserving the tabs).
 The latter is useful to tokenize text in tabulated data files.
 Because file I/O soon becomes the main bottleneck, having more than two or three parallel tokenizer processes ($GOMAXPROCS) running does not improve its speed any further.
+Compressed files (.gz, .bz2, .xz, and .zst, by extension or magic bytes) are read and written transparently, and gzip is decompressed in parallel, too.
 */
 package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/fnl/tokenizer"
 	"github.com/golang/glog"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"strconv"
 	"strings"
 )
 
+var abbrevs string
+var addr string
 var all bool
+var bio bool
+var cjkDictionary string
+var columnList string
+var csvMode bool
+var dehyphenate bool
+var elongations bool
+var format string
 var entities bool
 var lowercase bool
+var markdown bool
+var markup bool
+var maxBytes int64
+var ocr bool
+var outputFile string
+var outdir string
+var punktModel string
 var quotes bool
+var raw bool
+var recursive bool
+var scripts bool
+var segment bool
+var shapes bool
+var social bool
 var spaces bool
 var greek bool
+var grpcAddr string
+var header bool
+var hyphenated string
 var hyphens bool
+var include string
+var exclude string
+var inputFormat string
+var invalid string
+var jobs int
+var lexicon string
 var split bool
+var tags bool
+var thaiDictionary string
+var textField string
+var tokensField string
+var top int
 var tsv bool
+var uax29 bool
+var update bool
+var workers int
+var truecaseModel string
 var cpuProfileFile string
 var heapProfileFile string
 
 func init() {
+	flag.StringVar(&addr, "addr", "localhost:8080", "the address to serve on (with serve)")
+	flag.StringVar(&abbrevs, "abbrevs", "", "keep the period of abbreviations from a comma-separated list of languages ("+
+		strings.Join(tokenizer.AbbreviationLanguages(), ", ")+") or files")
 	flag.BoolVar(&all, "all", false, "enable -entities, -lowercase, -quotes, -greek and -hyphens")
+	flag.BoolVar(&bio, "bio", false, "keep biomedical names intact")
+	flag.StringVar(&cjkDictionary, "cjk", "", "segment Chinese and Japanese with the dictionary file (implies -segment)")
+	flag.StringVar(&columnList, "columns", "", "only tokenize these comma-separated columns (from 1) of -tsv or -csv input")
+	flag.BoolVar(&csvMode, "csv", false, "tokenize the fields of CSV (RFC 4180) input")
+	flag.BoolVar(&dehyphenate, "dehyphenate", false, "join words broken by a hyphen at the end of a line")
+	flag.BoolVar(&elongations, "elongations", false, "shorten elongated words (\"sooooo\" -> \"sooo\")")
+	flag.StringVar(&format, "format", "text", "write text (tokens separated by spaces or newlines),\njsonl (a JSON object with the tokens' class, value, and byte offsets per line),\nconll (CoNLL-U: a token per line, with a text comment before and a blank line after each input),\nor the tokens' character offsets in the file: brat (BRAT .ann annotations) or standoff (start, end, and class TSV)")
 	flag.BoolVar(&entities, "entities", false, "unescape HTML entities")
+	flag.StringVar(&exclude, "exclude", "", "skip the files and directories matching these comma-separated glob patterns (with -r)")
 	flag.BoolVar(&greek, "greek", false, "expand Greek letters")
+	flag.StringVar(&grpcAddr, "grpcaddr", "", "the address to also serve the streaming gRPC Tokenizer service on (with serve)")
+	flag.BoolVar(&header, "header", false, "pass the first row of -tsv or -csv input through untouched")
+	flag.StringVar(&hyphenated, "hyphenated", "keep", "keep, split, attach (split after hyphens), or lexicon (split known parts) hyphenated words")
 	flag.BoolVar(&hyphens, "hyphens", false, "map dashes and hyphens")
+	flag.StringVar(&include, "include", "", "only tokenize the files matching these comma-separated glob patterns (with -r)")
+	flag.StringVar(&inputFormat, "input", "text", "read text (lines) or jsonl (JSON records with a -textfield)\nand write jsonl records with a -tokensfield added")
+	flag.StringVar(&invalid, "invalid", "ignore", "ignore, emit (quoted), replace (with U+FFFD), or fail (the line) on invalid UTF-8 and control characters;\nreport their counts per file to STDERR")
+	flag.IntVar(&jobs, "jobs", runtime.NumCPU(), "the number of files to tokenize in parallel (with -outdir)")
+	flag.StringVar(&lexicon, "lexicon", "", "the dictionary file of known words for -hyphenated lexicon, -dehyphenate, and -ocr")
 	flag.BoolVar(&lowercase, "lowercase", false, "lowercase words")
+	flag.BoolVar(&markdown, "markdown", false, "strip Markdown syntax")
+	flag.Int64Var(&maxBytes, "maxbytes", 1<<20, "the maximum size of a request body (with serve)")
+	flag.BoolVar(&markup, "markup", false, "strip HTML/XML markup (tags and comments may span lines)")
+	flag.StringVar(&outputFile, "o", "", "write to this file instead of STDOUT, compressed if it ends in .gz, .bz2, .xz, or .zst")
+	flag.BoolVar(&ocr, "ocr", false, "clean up OCR and PDF text (implies -dehyphenate)")
+	flag.StringVar(&outdir, "outdir", "", "write the output for each FILE to a file of the same name in this directory\n(mirroring the directories below a directory FILE),\nwith the extension of the -format (.tok, .jsonl, .conllu, .ann (next to a .txt copy), or .tsv)")
+	flag.StringVar(&punktModel, "punkt", "", "keep the period of abbreviations from the Punkt model file (see train-abbrevs)")
 	flag.BoolVar(&quotes, "quotes", false, "normalize quotes")
+	flag.BoolVar(&recursive, "r", false, "tokenize the files in directory FILEs and their subdirectories")
+	flag.BoolVar(&raw, "raw", false, "prefix tokens with their raw form and a tab (forces -split)")
+	flag.BoolVar(&scripts, "scripts", false, "split words at script changes and add a script column (forces -split)")
+	flag.BoolVar(&segment, "segment", false, "segment Han, kana, and Thai runs")
+	flag.BoolVar(&shapes, "shapes", false, "add word shape and feature columns (forces -split)")
+	flag.BoolVar(&social, "social", false, "lex hashtags, mentions, cashtags, and emoticons")
 	flag.BoolVar(&split, "split", false, "split tokens by newlines (default: spaces)")
 	flag.BoolVar(&spaces, "spaces", false, "emit spaces (forces -split)")
+	flag.BoolVar(&tags, "tags", false, "emit HTML/XML tags as tokens (implies -markup)")
+	flag.StringVar(&textField, "textfield", "text", "the field of JSON records to tokenize (with -input jsonl)")
+	flag.StringVar(&thaiDictionary, "thai", "", "segment Thai with the dictionary file (implies -segment)")
 	flag.BoolVar(&tsv, "tsv", false, "maintain tab-separation of input")
+	flag.StringVar(&tokensField, "tokensfield", "tokens", "the field of JSON records to write the tokens to (with -input jsonl)")
+	flag.IntVar(&top, "top", 10, "the number of most frequent words to report (with stats)")
+	flag.StringVar(&truecaseModel, "truecase", "", "truecase words using the model file (see train-truecase)")
+	flag.BoolVar(&update, "update", false, "skip FILEs whose -outdir output is newer than them")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "the number of texts (or gRPC streams) to tokenize at once (with serve);\neach gets a new lexer with its options, the others wait for a free one")
+	flag.BoolVar(&uax29, "uax29", false, "segment at Unicode word boundaries (UAX #29) like ICU's StandardTokenizer")
 	flag.StringVar(&cpuProfileFile, "pprof", "", "write CPU profile to file")
 	flag.StringVar(&heapProfileFile, "mprof", "", "write heap profile to file")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [Options] [FILE ...]\n\nOptions:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "usage: %s [Command] [Options] [FILE ...]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Commands:")
+		fmt.Fprintln(os.Stderr, "  serve\n    \ttokenize texts POSTed to /tokenize at the -addr, as plain text or JSON\n    \t({\"text\": ...} or {\"texts\": [...]}, with optional \"options\": [...]\n    \tor an options=... query parameter that replace all of the server's options,\n    \tbut use its models, like -truecase or -lexicon),\n    \tand answer with JSON tokens (see -format jsonl); GET /health checks the service;\n    \twith -grpcaddr, also stream documents and tokens over gRPC (see service/tokenizer.proto)")
+		fmt.Fprintln(os.Stderr, "  stats\n    \twrite a table (or with -format jsonl, JSON objects) of token statistics\n    \tfor each input file and for all of them to STDOUT")
+		fmt.Fprintln(os.Stderr, "  train-abbrevs\n    \twrite a Punkt model of abbreviations, collocations,\n    \tand sentence starters learned from the input to STDOUT")
+		fmt.Fprintln(os.Stderr, "  train-truecase\n    \twrite a truecasing model learned from the input to STDOUT")
+		fmt.Fprintln(os.Stderr, "\nOptions:")
 		flag.PrintDefaults()
 	}
 }
 
 func main() {
 	var options tokenizer.Option
+	resources := &tokenizer.Resources{}
+	command := ""
 	sep := " "
 
+	if len(os.Args) > 1 && (os.Args[1] == "serve" || os.Args[1] == "stats" || os.Args[1] == "train-abbrevs" || os.Args[1] == "train-truecase") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	flag.Parse()
 
-	if split || spaces {
+	if split || spaces || raw || shapes || scripts {
 		sep = "\n"
 	}
 
@@ -67,6 +165,15 @@ func main() {
 			tokenizer.Hyphens |
 			tokenizer.Greek
 	}
+	if bio {
+		options |= tokenizer.Bio
+	}
+	if dehyphenate {
+		options |= tokenizer.Dehyphenate
+	}
+	if elongations {
+		options |= tokenizer.Elongations
+	}
 	if entities {
 		options |= tokenizer.Entities
 	}
@@ -79,9 +186,146 @@ func main() {
 	if lowercase {
 		options |= tokenizer.Lowercase
 	}
+	if markdown {
+		options |= tokenizer.Markdown
+	}
+	if markup {
+		options |= tokenizer.Markup
+	}
+	if ocr {
+		options |= tokenizer.OCR
+	}
 	if quotes {
 		options |= tokenizer.Quotes
 	}
+	if social {
+		options |= tokenizer.Social
+	}
+	if tags {
+		options |= tokenizer.Tags
+	}
+	if segment {
+		options |= tokenizer.Segment
+	}
+	if cjkDictionary != "" {
+		options |= tokenizer.Segment
+		resources.CJK = readDictionary(cjkDictionary)
+	}
+	if thaiDictionary != "" {
+		options |= tokenizer.Segment
+		resources.Thai = readDictionary(thaiDictionary)
+	}
+	if shapes {
+		options |= tokenizer.Shapes
+	}
+	if scripts {
+		options |= tokenizer.Scripts
+	}
+	if abbrevs != "" {
+		options |= tokenizer.Abbrevs
+		resources.Abbreviations = readAbbreviations(strings.Split(abbrevs, ","))
+	}
+	if punktModel != "" {
+		options |= tokenizer.Abbrevs
+
+		if resources.Abbreviations == nil {
+			resources.Abbreviations = tokenizer.NewAbbreviations(nil)
+		}
+
+		resources.Abbreviations.Merge(readPunkt(punktModel).Abbreviations)
+	}
+	if lexicon != "" {
+		resources.Lexicon = readDictionary(lexicon)
+	}
+	switch hyphenated {
+	case "keep":
+	case "split":
+		options |= tokenizer.SplitHyphenated
+	case "attach":
+		options |= tokenizer.AttachHyphenated
+	case "lexicon":
+		if lexicon == "" {
+			glog.Fatalln("-hyphenated lexicon requires a -lexicon")
+		}
+		options |= tokenizer.LexiconHyphenated
+	default:
+		glog.Fatalf("unknown -hyphenated policy %q\n", hyphenated)
+	}
+	switch invalid {
+	case "ignore":
+	case "emit":
+		options |= tokenizer.EmitInvalid
+	case "replace":
+		options |= tokenizer.ReplaceInvalid
+	case "fail":
+		options |= tokenizer.FailInvalid
+	default:
+		glog.Fatalf("unknown -invalid policy %q\n", invalid)
+	}
+	if uax29 {
+		options |= tokenizer.UAX29
+	}
+	if extensions[format] == "" {
+		glog.Fatalf("unknown -format %q\n", format)
+	} else if command == "stats" && format != "text" && format != "jsonl" {
+		glog.Fatalln("stats only writes -format text or jsonl")
+	} else if command == "stats" && (tsv || csvMode) {
+		// (and so with -columns and -header)
+		glog.Fatalln("stats is incompatible with -tsv and -csv")
+	}
+	if inputFormat != "text" && inputFormat != "jsonl" {
+		glog.Fatalf("unknown -input format %q\n", inputFormat)
+	}
+	if (format == "jsonl" || inputFormat == "jsonl") && tsv {
+		glog.Fatalln("-format jsonl and -input jsonl are incompatible with -tsv")
+	}
+	if format == "conll" && (tsv || inputFormat == "jsonl") {
+		glog.Fatalln("-format conll is incompatible with -tsv and -input jsonl")
+	}
+	if (raw || shapes || scripts) && (tsv || csvMode) {
+		glog.Fatalln("-raw, -shapes, and -scripts are incompatible with -tsv and -csv")
+	}
+	if csvMode && (tsv || format != "text" || inputFormat != "text") {
+		glog.Fatalln("-csv is incompatible with -tsv, -format, and -input")
+	}
+	if (columnList != "" || header) && !tsv && !csvMode {
+		glog.Fatalln("-columns and -header need -tsv or -csv")
+	}
+	if columnList != "" {
+		selectedColumns = parseColumns(columnList)
+	}
+	if tableMode() && (dehyphenate || ocr) {
+		// (the lexers would join words across fields)
+		glog.Fatalln("-dehyphenate and -ocr are incompatible with -csv and -columns")
+	}
+	if (raw || shapes || scripts) && format == "conll" {
+		glog.Fatalln("-raw, -shapes, and -scripts are incompatible with -format conll")
+	}
+	if standoffFormat() && (tsv || inputFormat == "jsonl") {
+		glog.Fatalln("-format brat and standoff are incompatible with -tsv and -input jsonl")
+	}
+	if outdir != "" {
+		if outputFile != "" {
+			glog.Fatalln("-o and -outdir are incompatible options")
+		} else if flag.NArg() == 0 || command != "" {
+			glog.Fatalln("-outdir needs FILE arguments to tokenize")
+		} else if err := os.MkdirAll(outdir, 0755); err != nil {
+			glog.Fatalf("creating -outdir %q failed: %s\n", outdir, err)
+		} else if jobs < 1 {
+			glog.Fatalln("-jobs must be positive")
+		}
+	} else if update {
+		glog.Fatalln("-update needs an -outdir")
+	}
+	for _, patterns := range []string{include, exclude} {
+		if _, err := filepath.Match(patterns, ""); err != nil {
+			glog.Fatalf("invalid glob pattern in %q: %s\n", patterns, err)
+		}
+	}
+	if truecaseModel != "" {
+		options |= tokenizer.Truecase
+		resources.Truecaser = readTruecaser(truecaseModel)
+	}
 	if spaces || tsv {
 		options |= tokenizer.Spaces
 
@@ -106,19 +350,38 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	if flag.NArg() > 0 {
-		for _, path := range flag.Args() {
-			file, err := os.Open(path)
+	var output io.Writer = os.Stdout
 
-			if err != nil {
-				glog.Fatalf("reading %q failed: %s\n", path, err)
-			}
+	if outputFile != "" {
+		file := openOutput(outputFile)
+		output = file
+		defer closeStream(outputFile, file)
+	}
 
-			defer file.Close()
-			tokenize(file, options, sep)
+	if command == "serve" {
+		if flag.NArg() > 0 {
+			glog.Fatalln("serve takes no FILE arguments")
+		} else if workers < 1 || maxBytes < 1 {
+			glog.Fatalln("-workers and -maxbytes must be positive")
+		}
+
+		serve(options, resources)
+	} else if command == "stats" {
+		writeStatistics(options, resources, output)
+	} else if command == "train-abbrevs" {
+		trainPunkt(options, output)
+	} else if command == "train-truecase" {
+		trainTruecaser(options, output)
+	} else if inputs := findInputs(flag.Args()); outdir != "" {
+		tokenizeFiles(inputs, options, resources, sep)
+	} else if flag.NArg() > 0 {
+		for _, input := range inputs {
+			file := openInput(input.path)
+			tokenize(input.path, file, output, options, resources, sep)
+			closeStream(input.path, file)
 		}
 	} else {
-		tokenize(os.Stdin, options, sep)
+		tokenize("-", openInput("-"), output, options, resources, sep)
 	}
 
 	if heapProfileFile != "" {
@@ -145,53 +408,173 @@ func min(a, b int) int {
 	}
 }
 
-func tokenize(file io.Reader, options tokenizer.Option, sep string) {
+// the options that carry state from one line to the next
+const statefulOptions = tokenizer.Markup | tokenizer.Tags | tokenizer.Markdown | tokenizer.Dehyphenate | tokenizer.OCR
+
+// an input line, as sent along to convertTokens
+type line struct {
+	text   string // the line (or its JSON record)
+	offset int    // the rune offset of the line in its file
+	row    *row   // the table row (with -csv, or -tsv and -columns)
+}
+
+// tokenize the lines of a file with several lexers (one, if they carry state),
+// sending the lines to the lexers in turn,
+// so their results can be written back in the same order
+func tokenize(name string, file io.Reader, w io.Writer, options tokenizer.Option, resources *tokenizer.Resources, sep string) {
 	n := min(runtime.GOMAXPROCS(0), runtime.NumCPU())
-	input := make(chan string, n)
-	output := make(chan string, n)
-	semaphore := make(chan int)
+
+	// (BRAT annotation ids run through the file)
+	if options&statefulOptions != 0 || format == "brat" {
+		n = 1
+	}
+
+	inputs := make([]chan string, n)
+	records := make([]chan line, n)
+	outputs := make([]chan string, n)
+	done := make(chan int)
+	found := &problems{}
 
 	for i := 0; i < n; i++ {
-		tokens := tokenizer.Lex(input, 50, options)
-		go convertTokens(tokens, sep, output, semaphore)
+		inputs[i] = make(chan string, 1)
+		records[i] = make(chan line, 100)
+		outputs[i] = make(chan string, 10)
+		tokens := tokenizer.LexWith(found.check(inputs[i]), 50, options, resources)
+		go convertTokens(tokens, sep, records[i], outputs[i], found)
 	}
 
-	go writeResults(output, semaphore)
+	go writeResults(outputs, w, done)
 
+	if tableMode() {
+		readTable(name, file, w, inputs, records)
+	} else {
+		readText(name, file, w, inputs, records)
+	}
+
+	for i := 0; i < n; i++ {
+		close(inputs[i])
+		close(records[i])
+	}
+
+	<-done
+	found.report(name)
+	glog.Flush()
+}
+
+// readText sends the lines of a file to the lexers in turn,
+// and the lines (or JSON records) along to convertTokens, if needed;
+// writes a -header line to w as it is
+func readText(name string, file io.Reader, w io.Writer, inputs []chan string, records []chan line) {
+	n := len(inputs)
+	offsets := &lineOffsets{}
 	scanner := bufio.NewScanner(file)
-	scanner.Split(bufio.ScanLines)
+	scanner.Split(offsets.scanLines)
 
-	for scanner.Scan() {
-		input <- scanner.Text()
+	if header && scanner.Scan() {
+		fmt.Fprintln(w, scanner.Text())
 	}
 
-	close(input)
+	for i := 0; scanner.Scan(); i++ {
+		text := scanner.Text()
+
+		if inputFormat == "jsonl" || format == "conll" || standoffFormat() {
+			records[i%n] <- line{text: text, offset: offsets.offset}
+		}
+		if inputFormat == "jsonl" {
+			var err error
 
-	for n > 0 {
-		<-semaphore
-		n--
+			if text, err = recordText(text, i+1); err != nil {
+				glog.Fatalln(err)
+			}
+		}
+
+		inputs[i%n] <- text
 	}
 
-	close(output)
-	<-semaphore
-	glog.Flush()
+	if err := scanner.Err(); err != nil {
+		glog.Fatalf("reading %q failed: %s\n", name, err)
+	}
 }
 
-func convertTokens(in chan tokenizer.Token, sep string, out chan string, done chan int) {
+// convertTokens formats the tokens of each input line
+// (and adds them to its JSON record, if reading jsonl)
+func convertTokens(in chan tokenizer.Token, sep string, records chan line, out chan string, found *problems) {
 	var buffer []string
+	var sentence tokenizer.Sentence
+	objects := []jsonToken{}
 	tsvOffset := 0
+	annotations := 0
+	rows := &tableRows{records: records}
 
 	for token := range in {
+		found.count(token)
+
+		if format == "jsonl" && !token.IsEnd() {
+			objects = append(objects, newJSONToken(token))
+			continue
+		} else if format == "conll" || standoffFormat() {
+			if !token.IsEnd() {
+				sentence.Tokens = append(sentence.Tokens, token)
+				continue
+			}
+
+			input := <-records
+
+			if format == "conll" {
+				sentence.Text = input.text
+				out <- conllSentence(&sentence)
+			} else {
+				var result string
+				result, annotations = standoff(sentence.Tokens, input, annotations)
+				out <- result
+			}
+
+			sentence.Tokens = sentence.Tokens[:0]
+			continue
+		}
+
 		switch token.Class {
+		case tokenizer.ErrorToken:
+			// reported by found
 		case tokenizer.EndToken:
-			if tsv {
+			var result string
+
+			if format == "jsonl" {
+				result = string(marshal(objects))
+				objects = objects[:0]
+			} else if tsv {
 				buffer, tsvOffset = tsvTokenizer(buffer, tsvOffset, sep)
-				out <- strings.Join(buffer, "\t")
+				result = strings.Join(buffer, "\t")
 				tsvOffset = 0
 			} else {
-				out <- strings.Join(buffer, sep)
+				result = strings.Join(buffer, sep)
+			}
+
+			if tableMode() {
+				if result, ok := rows.add(result); ok {
+					out <- result
+				}
+
+				buffer = buffer[:0]
+				continue
 			}
+
+			if inputFormat == "jsonl" {
+				if format == "text" {
+					result = string(marshal(result))
+				}
+
+				record := <-records
+				result = withField(record.text, tokensField, json.RawMessage(result))
+			} else if format == "jsonl" {
+				result = withField("{}", tokensField, json.RawMessage(result))
+			}
+
+			out <- result
 			buffer = buffer[:0]
+		case tokenizer.InvalidToken:
+			token.Value = strconv.Quote(token.Value)
+			fallthrough
 		default:
 			if tsv && token.IsSpace() && strings.ContainsRune(token.Value, '\t') {
 				buffer, tsvOffset = tsvTokenizer(buffer, tsvOffset, sep)
@@ -202,12 +585,41 @@ func convertTokens(in chan tokenizer.Token, sep string, out chan string, done ch
 					buffer = append(buffer, "")
 					tsvOffset++
 				}
+			} else if raw || shapes || scripts {
+				buffer = append(buffer, columns(token))
 			} else if !tsv || !token.IsSpace() {
 				buffer = append(buffer, token.Value)
 			}
 		}
 	}
-	done <- 1
+
+	close(out)
+}
+
+// conllSentence formats a sentence in CoNLL-U,
+// without the final newline
+func conllSentence(sentence *tokenizer.Sentence) string {
+	var result strings.Builder
+	// writing to a strings.Builder never fails:
+	_, _ = sentence.WriteTo(&result)
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+// columns formats a token with the raw, shape, and script columns requested
+func columns(token tokenizer.Token) string {
+	value := token.Value
+
+	if raw {
+		value = token.Raw + "\t" + value
+	}
+	if shapes {
+		value = fmt.Sprintf("%s\t%s\t%s", value, token.Shape, token.Features)
+	}
+	if scripts {
+		value = value + "\t" + token.Script
+	}
+
+	return value
 }
 
 func tsvTokenizer(buffer []string, tsvOffset int, sep string) ([]string, int) {
@@ -224,9 +636,21 @@ func tsvTokenizer(buffer []string, tsvOffset int, sep string) ([]string, int) {
 	return buffer, tsvOffset + 1
 }
 
-func writeResults(lines chan string, done chan int) {
-	for l := range lines {
-		fmt.Println(l)
+// writeResults prints the results of the lexers in turn,
+// in the order their inputs were sent
+// (standoff annotations end with a newline already)
+func writeResults(outputs []chan string, w io.Writer, done chan int) {
+	for i := 0; ; i++ {
+		line, ok := <-outputs[i%len(outputs)]
+
+		if !ok {
+			break
+		} else if standoffFormat() {
+			fmt.Fprint(w, line)
+		} else {
+			fmt.Fprintln(w, line)
+		}
 	}
+
 	done <- 1
 }
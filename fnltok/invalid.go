@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"github.com/fnl/tokenizer"
+	"os"
+	"sync/atomic"
+)
+
+// the invalid input found in a file
+type problems struct {
+	invalid int64 // invalid UTF-8 bytes
+	control int64 // control characters
+	failed  int64 // lines failed (with -invalid fail)
+}
+
+// count the lines a token reports as failed
+func (p *problems) count(token tokenizer.Token) {
+	if token.IsError() {
+		atomic.AddInt64(&p.failed, 1)
+	}
+}
+
+// check counts the invalid bytes and control characters
+// in the texts sent to the input channel (whatever the -invalid policy)
+// and passes them on to the returned channel, for a lexer
+func (p *problems) check(input chan string) chan string {
+	checked := make(chan string, cap(input))
+
+	go func() {
+		for text := range input {
+			bytes, controls := tokenizer.CountInvalid(text)
+			atomic.AddInt64(&p.invalid, int64(bytes))
+			atomic.AddInt64(&p.control, int64(controls))
+			checked <- text
+		}
+
+		close(checked)
+	}()
+
+	return checked
+}
+
+// report the problems found in a file to STDERR (if any)
+func (p *problems) report(name string) {
+	if p.invalid+p.control+p.failed > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d invalid UTF-8 bytes, %d control characters, %d failed lines\n",
+			name, p.invalid, p.control, p.failed)
+	}
+}
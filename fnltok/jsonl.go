@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/fnl/tokenizer"
+	"github.com/golang/glog"
+	"strings"
+)
+
+// a token, as written by -format jsonl
+type jsonToken struct {
+	Class    string `json:"class"`
+	Value    string `json:"value"`
+	Start    int    `json:"start"`              // byte offset in the input line
+	End      int    `json:"end"`                // byte offset in the input line
+	Raw      string `json:"raw,omitempty"`      // with -raw
+	Shape    string `json:"shape,omitempty"`    // with -shapes
+	Features string `json:"features,omitempty"` // with -shapes
+	Script   string `json:"script,omitempty"`   // with -scripts
+}
+
+// newJSONToken converts a token, adding the fields requested
+func newJSONToken(token tokenizer.Token) jsonToken {
+	t := jsonToken{
+		Class: token.ClassName(),
+		Value: token.Value,
+		Start: token.Start,
+		End:   token.End,
+	}
+
+	if raw {
+		t.Raw = token.Raw
+	}
+	if shapes {
+		t.Shape = token.Shape
+		t.Features = token.Features.String()
+	}
+	if scripts {
+		t.Script = token.Script
+	}
+
+	return t
+}
+
+// marshal encodes a value as JSON
+func marshal(value interface{}) json.RawMessage {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		glog.Fatalf("encoding JSON failed: %s\n", err)
+	}
+
+	return data
+}
+
+// recordText extracts the text field from a JSON record
+func recordText(record string, line int) (string, error) {
+	var fields map[string]json.RawMessage
+	var text string
+
+	if err := json.Unmarshal([]byte(record), &fields); err != nil {
+		return "", fmt.Errorf("line %d: decoding JSON record failed: %s", line, err)
+	} else if value, ok := fields[textField]; !ok {
+		return "", fmt.Errorf("line %d: JSON record lacks a %q field", line, textField)
+	} else if err = json.Unmarshal(value, &text); err != nil {
+		return "", fmt.Errorf("line %d: the %q field is no string: %s", line, textField, err)
+	}
+
+	return text, nil
+}
+
+// withField adds a field to the end of a JSON record,
+// or replaces the value of the field in place,
+// keeping the record's other fields as they are
+func withField(record string, name string, value json.RawMessage) string {
+	// the record is known to be valid from recordText:
+	decoder := json.NewDecoder(strings.NewReader(record))
+	_, _ = decoder.Token() // "{"
+	start, end, fields := -1, -1, 0
+
+	for ; decoder.More(); fields++ {
+		key, _ := decoder.Token()
+		var raw json.RawMessage
+		_ = decoder.Decode(&raw)
+
+		if key == name {
+			// (the last of duplicate fields, as when unmarshaled)
+			end = int(decoder.InputOffset())
+			start = end - len(raw)
+		}
+	}
+
+	if start != -1 {
+		return record[:start] + string(value) + record[end:]
+	}
+
+	object := bytes.TrimSpace([]byte(record))
+	object = bytes.TrimSuffix(object, []byte("}"))
+	field := append(marshal(name), ':')
+
+	if fields > 0 {
+		field = append([]byte{','}, field...)
+	}
+
+	return string(object) + string(field) + string(value) + "}"
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRecordText(t *testing.T) {
+	for _, test := range []struct {
+		record   string
+		expected string
+		err      string
+	}{
+		{`{"id": 1, "text": "a \"b\""}`, `a "b"`, ""},
+		{`{"text": "first", "text": "last"}`, "last", ""},
+		{`{"id": 1}`, "", `line 7: JSON record lacks a "text" field`},
+		{`{}`, "", `line 7: JSON record lacks a "text" field`},
+		{`{"text": 3}`, "", `line 7: the "text" field is no string`},
+		{`{"text": null}`, "", ""},
+		{`["text"]`, "", "line 7: decoding JSON record failed"},
+	} {
+		text, err := recordText(test.record, 7)
+
+		if test.err == "" && err != nil {
+			t.Errorf("%s: unexpected error %s", test.record, err)
+		} else if test.err != "" && (err == nil || !strings.HasPrefix(err.Error(), test.err)) {
+			t.Errorf("%s: expected the error %q, got %v", test.record, test.err, err)
+		} else if text != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.record, test.expected, text)
+		}
+	}
+}
+
+func TestWithField(t *testing.T) {
+	for _, test := range []struct {
+		description string
+		record      string
+		expected    string
+	}{
+		{"replace in place", `{"z": 1, "tokens": [ 9 ], "a": {"tokens": 2}}`,
+			`{"z": 1, "tokens": ["x"], "a": {"tokens": 2}}`},
+		{"replace at the end", `{ "text":"a b","tokens" : "old" }`,
+			`{ "text":"a b","tokens" : ["x"] }`},
+		{"append", `{"text": "a", "b": [1, {"c": null}]}`,
+			`{"text": "a", "b": [1, {"c": null}],"tokens":["x"]}`},
+		{"append with spaces", ` {"text": "a"}  `,
+			`{"text": "a","tokens":["x"]}`},
+		{"empty object", `{}`,
+			`{"tokens":["x"]}`},
+		{"empty object with spaces", `{ }`,
+			`{ "tokens":["x"]}`},
+		{"duplicate keys", `{"tokens": 1, "text": "a", "tokens": 2}`,
+			`{"tokens": 1, "text": "a", "tokens": ["x"]}`},
+		{"escaped key", `{"tok\u0065ns": 1}`,
+			`{"tok\u0065ns": ["x"]}`},
+	} {
+		result := withField(test.record, tokensField, json.RawMessage(`["x"]`))
+
+		if result != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.description, test.expected, result)
+		} else if !json.Valid([]byte(result)) {
+			t.Errorf("%s: invalid JSON %s", test.description, result)
+		}
+	}
+}
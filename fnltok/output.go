@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"github.com/fnl/tokenizer"
+	"github.com/golang/glog"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// the file extensions of the output formats (with -outdir)
+var extensions = map[string]string{
+	"text":     ".tok",
+	"jsonl":    ".jsonl",
+	"conll":    ".conllu",
+	"brat":     ".ann",
+	"standoff": ".tsv",
+}
+
+// an input file, and the path of its outputs in the -outdir
+// (without their extension)
+type inputFile struct {
+	path string
+	stem string
+}
+
+// stem strips the extension (and compression extension) off a path
+func stem(path string) string {
+	if _, ok := compressions[filepath.Ext(path)]; ok {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// true if the name matches any of the comma-separated glob patterns
+func matchesAny(name, patterns string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findInputs lists the files to tokenize: the file arguments,
+// and (with -r) the files in the directory arguments
+// that match the -include and not the -exclude patterns,
+// skipping the -outdir (if in them) and unfinished (.part) outputs;
+// their outputs mirror the directories below the -outdir
+func findInputs(paths []string) []inputFile {
+	var inputs []inputFile
+	stems := make(map[string]string)
+
+	add := func(path, name string) {
+		input := inputFile{path, filepath.Join(outdir, stem(name))}
+
+		if other, ok := stems[input.stem]; ok && outdir != "" {
+			glog.Fatalf("the outputs of %q and %q would be the same\n", other, path)
+		}
+
+		stems[input.stem] = path
+		inputs = append(inputs, input)
+	}
+
+	for _, root := range paths {
+		if info, err := os.Stat(root); root == "-" || err == nil && !info.IsDir() {
+			add(root, filepath.Base(root))
+			continue
+		} else if err != nil {
+			glog.Fatalf("reading %q failed: %s\n", root, err)
+		} else if !recursive {
+			glog.Fatalf("%q is a directory (see -r)\n", root)
+		}
+
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			} else if path != root && entry.IsDir() && outdir != "" && sameFile(path, outdir) {
+				return filepath.SkipDir // (earlier outputs)
+			} else if path != root && exclude != "" && matchesAny(entry.Name(), exclude) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+			} else if !entry.IsDir() && filepath.Ext(path) != ".part" &&
+				(include == "" || matchesAny(entry.Name(), include)) {
+				name, _ := filepath.Rel(root, path)
+				add(path, name)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			glog.Fatalf("reading %q failed: %s\n", root, err)
+		}
+	}
+
+	return inputs
+}
+
+// true if the output exists and is not older than the input
+func upToDate(input, output string) bool {
+	inputInfo, err := os.Stat(input)
+
+	if err != nil {
+		return false
+	}
+
+	outputInfo, err := os.Stat(output)
+	return err == nil && !outputInfo.ModTime().Before(inputInfo.ModTime())
+}
+
+// true if both paths name the same (existing) file
+func sameFile(path, other string) bool {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return false
+	}
+
+	otherInfo, err := os.Stat(other)
+	return err == nil && os.SameFile(info, otherInfo)
+}
+
+// create a file, unless it would overwrite the input file
+func createOutput(path, input string) *os.File {
+	if sameFile(path, input) {
+		glog.Fatalf("writing %q would overwrite the input\n", path)
+	}
+
+	file, err := os.Create(path)
+
+	if err != nil {
+		glog.Fatalf("creating %q failed: %s\n", path, err)
+	}
+
+	return file
+}
+
+// closeOutput closes a file written to, failing on errors
+func closeOutput(file *os.File) {
+	if err := file.Close(); err != nil {
+		glog.Fatalf("writing %q failed: %s\n", file.Name(), err)
+	}
+}
+
+// tokenizeFiles tokenizes the input files to the -outdir, -jobs files at a time
+func tokenizeFiles(inputs []inputFile, options tokenizer.Option, resources *tokenizer.Resources, sep string) {
+	queue := make(chan inputFile)
+	var wg sync.WaitGroup
+
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for input := range queue {
+				tokenizeFile(input, options, resources, sep)
+			}
+		}()
+	}
+
+	for _, input := range inputs {
+		queue <- input
+	}
+
+	close(queue)
+	wg.Wait()
+}
+
+// tokenizeFile tokenizes an input file to its output file in the -outdir,
+// unless the output is up to date (with -update);
+// with -format brat, it also copies the input there as a .txt file,
+// unless the input already is that file
+// (and the output is only up to date if the copy is, too)
+func tokenizeFile(input inputFile, options tokenizer.Option, resources *tokenizer.Resources, sep string) {
+	extension := extensions[format]
+
+	if inputFormat == "jsonl" {
+		extension = extensions["jsonl"]
+	}
+
+	path := input.stem + extension
+	text := input.stem + ".txt"
+	copies := format == "brat" && !sameFile(text, input.path)
+
+	if update && upToDate(input.path, path) && (!copies || upToDate(input.path, text)) {
+		return
+	} else if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		glog.Fatalf("creating %q failed: %s\n", filepath.Dir(path), err)
+	} else if sameFile(path, input.path) {
+		glog.Fatalf("writing %q would overwrite the input\n", path)
+	}
+
+	file := openInput(input.path)
+	// (written under another name first, so an interrupted output is never up to date)
+	output := createOutput(path+".part", input.path)
+	writer := bufio.NewWriter(output)
+	var reader io.Reader = file
+
+	if copies {
+		source := createOutput(text, input.path)
+		defer closeOutput(source)
+		reader = io.TeeReader(file, source)
+	}
+
+	tokenize(input.path, reader, writer, options, resources, sep)
+	closeStream(input.path, file)
+
+	if err := writer.Flush(); err != nil {
+		glog.Fatalf("writing %q failed: %s\n", output.Name(), err)
+	}
+
+	closeOutput(output)
+
+	if err := os.Rename(output.Name(), path); err != nil {
+		glog.Fatalf("renaming %q failed: %s\n", output.Name(), err)
+	}
+}
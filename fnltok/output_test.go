@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// create the (empty) files below a directory
+func createFiles(t *testing.T, dir string, paths ...string) {
+	for _, path := range paths {
+		path = filepath.Join(dir, path)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		} else if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	for path, expected := range map[string]string{
+		"a":            "a",
+		"a.txt":        "a",
+		"a.b.txt":      "a.b",
+		"a.gz":         "a",
+		"dir/a.txt.gz": "dir/a",
+		"a.tar.zst":    "a",
+		"a.gz.txt":     "a.gz",
+	} {
+		if result := stem(path); result != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, result)
+		}
+	}
+}
+
+func TestFindInputs(t *testing.T) {
+	defer func(o, i, e string, r bool) {
+		outdir, include, exclude, recursive = o, i, e, r
+	}(outdir, include, exclude, recursive)
+	dir := t.TempDir()
+	createFiles(t, dir, "in/a.txt", "in/b.txt.part", "in/sub/c.txt.gz", "in/sub/d.md",
+		"in/out/a.tok", "in/out/e.txt", "f.txt")
+	outdir, include, exclude, recursive = filepath.Join(dir, "in", "out"), "", "*.md", true
+	var result []string
+
+	for _, input := range findInputs([]string{filepath.Join(dir, "in"), filepath.Join(dir, "f.txt")}) {
+		path, _ := filepath.Rel(dir, input.path)
+		stem, _ := filepath.Rel(dir, input.stem)
+		result = append(result, path+"="+stem)
+	}
+
+	expected := "[in/a.txt=in/out/a in/sub/c.txt.gz=in/out/sub/c f.txt=in/out/f]"
+
+	if fmt.Sprint(result) != expected {
+		t.Errorf("expected the inputs %s, got %v", expected, result)
+	}
+}
+
+func TestFindInputsDuplicates(t *testing.T) {
+	if dir := os.Getenv("FNLTOK_DUPLICATES"); dir != "" {
+		outdir, recursive = filepath.Join(dir, "out"), true
+		findInputs([]string{filepath.Join(dir, "in")})
+		return
+	}
+
+	defer func(o string, r bool) { outdir, recursive = o, r }(outdir, recursive)
+	dir := t.TempDir()
+	createFiles(t, dir, "in/a.txt", "in/a.csv.gz")
+	outdir, recursive = "", true
+
+	if inputs := findInputs([]string{filepath.Join(dir, "in")}); len(inputs) != 2 {
+		t.Errorf("expected two inputs without -outdir, got %v", inputs)
+	}
+
+	run := exec.Command(os.Args[0], "-test.run=^TestFindInputsDuplicates$")
+	run.Env = append(os.Environ(), "FNLTOK_DUPLICATES="+dir)
+	output, err := run.CombinedOutput()
+
+	if err == nil || !strings.Contains(string(output), "would be the same") {
+		t.Errorf("expected the duplicate outputs to fail, got %v: %s", err, output)
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	createFiles(t, dir, "input.txt", "output.tok")
+	input, output := filepath.Join(dir, "input.txt"), filepath.Join(dir, "output.tok")
+	now := time.Now()
+
+	for _, test := range []struct {
+		input    time.Time
+		output   time.Time
+		expected bool
+	}{
+		{now, now, true},
+		{now, now.Add(time.Minute), true},
+		{now.Add(time.Minute), now, false},
+	} {
+		if err := os.Chtimes(input, test.input, test.input); err != nil {
+			t.Fatal(err)
+		} else if err := os.Chtimes(output, test.output, test.output); err != nil {
+			t.Fatal(err)
+		} else if result := upToDate(input, output); result != test.expected {
+			t.Errorf("input at %s, output at %s: expected %t, got %t",
+				test.input, test.output, test.expected, result)
+		}
+	}
+
+	if upToDate(input, output+".part") || upToDate(input+".missing", output) {
+		t.Error("expected missing files not to be up to date")
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/fnl/tokenizer"
+	"github.com/golang/glog"
+	"io"
+)
+
+// trainPunkt learns a Punkt model from the input and writes it to w
+func trainPunkt(options tokenizer.Option, w io.Writer) {
+	input := make(chan string, 100)
+	model := make(chan *tokenizer.Punkt)
+	tokens := tokenizer.Lex(input, 100, options&^(tokenizer.Abbrevs|tokenizer.Truecase))
+
+	go func() {
+		model <- tokenizer.TrainPunkt(tokens)
+	}()
+
+	readLines(input)
+	close(input)
+
+	if _, err := (<-model).WriteTo(w); err != nil {
+		glog.Fatalf("writing Punkt model failed: %s\n", err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/fnl/tokenizer"
+	"github.com/golang/glog"
+	"os"
+)
+
+// readTruecaser loads a truecasing model from a file
+func readTruecaser(path string) *tokenizer.Truecaser {
+	file, err := os.Open(path)
+
+	if err != nil {
+		glog.Fatalf("reading %q failed: %s\n", path, err)
+	}
+
+	defer file.Close()
+	model, err := tokenizer.ReadTruecaser(file)
+
+	if err != nil {
+		glog.Fatalf("reading truecasing model %q failed: %s\n", path, err)
+	}
+
+	return model
+}
+
+// readDictionary loads a segmentation dictionary from a file
+func readDictionary(path string) *tokenizer.Dictionary {
+	file, err := os.Open(path)
+
+	if err != nil {
+		glog.Fatalf("reading %q failed: %s\n", path, err)
+	}
+
+	defer file.Close()
+	dictionary, err := tokenizer.ReadDictionary(file)
+
+	if err != nil {
+		glog.Fatalf("reading dictionary %q failed: %s\n", path, err)
+	}
+
+	return dictionary
+}
+
+// readAbbreviations collects the built-in abbreviations of languages
+// and the abbreviations listed in files
+func readAbbreviations(sources []string) *tokenizer.Abbreviations {
+	abbreviations := tokenizer.NewAbbreviations(nil)
+
+	for _, source := range sources {
+		if words := tokenizer.BuiltinAbbreviations(source); words != nil {
+			abbreviations.Merge(tokenizer.NewAbbreviations(words))
+			continue
+		}
+
+		file, err := os.Open(source)
+
+		if err != nil {
+			glog.Fatalf("reading %q failed (and no such language): %s\n", source, err)
+		}
+
+		list, err := tokenizer.ReadAbbreviations(file)
+		file.Close()
+
+		if err != nil {
+			glog.Fatalf("reading abbreviations %q failed: %s\n", source, err)
+		}
+
+		abbreviations.Merge(list)
+	}
+
+	return abbreviations
+}
+
+// readPunkt loads a Punkt model from a file
+func readPunkt(path string) *tokenizer.Punkt {
+	file, err := os.Open(path)
+
+	if err != nil {
+		glog.Fatalf("reading %q failed: %s\n", path, err)
+	}
+
+	defer file.Close()
+	model, err := tokenizer.ReadPunkt(file)
+
+	if err != nil {
+		glog.Fatalf("reading Punkt model %q failed: %s\n", path, err)
+	}
+
+	return model
+}
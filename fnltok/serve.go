@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fnl/tokenizer"
+	"github.com/fnl/tokenizer/service"
+	"github.com/golang/glog"
+	"google.golang.org/grpc"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// a tokenization request, as JSON
+type tokenizeRequest struct {
+	Text    *string  `json:"text"`    // a single text
+	Texts   []string `json:"texts"`   // or a batch of texts
+	Options []string `json:"options"` // the options to use (instead of the server's)
+}
+
+// the tokens of a text
+type tokenizeResult struct {
+	Tokens []jsonToken `json:"tokens"`
+}
+
+// a service that tokenizes texts with at most as many lexers at once as it has slots
+type httpService struct {
+	options   tokenizer.Option
+	resources *tokenizer.Resources
+	slots     chan struct{}
+	maxBytes  int64
+}
+
+// lex tokenizes a text with a new lexer, once a slot is free
+func (s *httpService) lex(ctx context.Context, text string, options tokenizer.Option) ([]jsonToken, error) {
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	input := make(chan string, 1)
+	input <- text
+	close(input)
+	objects := []jsonToken{}
+
+	for token := range tokenizer.LexWith(input, 100, options, s.resources) {
+		if token.IsEnd() {
+			continue
+		}
+
+		object := newJSONToken(token)
+		object.Raw = token.Raw
+
+		if options&tokenizer.Shapes != 0 {
+			object.Shape = token.Shape
+			object.Features = token.Features.String()
+		}
+		if options&tokenizer.Scripts != 0 {
+			object.Script = token.Script
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(marshal(value), '\n'))
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// tokenize handles POST requests with a plain text body,
+// or a JSON tokenizeRequest body (Content-Type application/json);
+// the options query parameter (a comma-separated list of names)
+// or the request's options replace all of the server's options
+// (but not its resources, so "truecase" uses the server's -truecase model)
+func (s *httpService) tokenize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return
+	}
+
+	var request tokenizeRequest
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBytes))
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+		} else {
+			writeError(w, http.StatusBadRequest, err)
+		}
+
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		if err := json.Unmarshal(body, &request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		} else if (request.Text == nil) == (request.Texts == nil) {
+			writeError(w, http.StatusBadRequest, errors.New(`expected either a "text" or "texts"`))
+			return
+		}
+	} else {
+		text := string(body)
+		request.Text = &text
+	}
+
+	options := s.options
+
+	if names := r.URL.Query().Get("options"); names != "" {
+		request.Options = append(request.Options, strings.Split(names, ",")...)
+	}
+
+	if request.Options != nil {
+		if options, err = tokenizer.ParseOptions(request.Options); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	texts := request.Texts
+
+	if request.Text != nil {
+		texts = []string{*request.Text}
+	}
+
+	results := make([]tokenizeResult, len(texts))
+
+	for i, text := range texts {
+		if results[i].Tokens, err = s.lex(r.Context(), text, options); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+	}
+
+	if request.Text != nil {
+		writeJSON(w, http.StatusOK, results[0])
+	} else {
+		writeJSON(w, http.StatusOK, map[string][]tokenizeResult{"results": results})
+	}
+}
+
+// health reports that the service is up, and the options it can use
+func (s *httpService) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "options": tokenizer.OptionNames()})
+}
+
+// handler routes the requests to /tokenize and /health
+func (s *httpService) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tokenize", s.tokenize)
+	mux.HandleFunc("/health", s.health)
+	return mux
+}
+
+// serve tokenizes texts over HTTP at the -addr
+// (and streams over gRPC at the -grpcaddr) until interrupted,
+// then finishes the pending requests (and streams) within 30 seconds
+func serve(options tokenizer.Option, resources *tokenizer.Resources) {
+	s := &httpService{
+		options:   options,
+		resources: resources,
+		slots:     make(chan struct{}, workers),
+		maxBytes:  maxBytes,
+	}
+	server := &http.Server{Addr: addr, Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	stopped := make(chan struct{})
+	var streams *grpc.Server
+
+	if grpcAddr != "" {
+		streams = grpc.NewServer()
+		service.RegisterTokenizerServer(streams, service.NewServer(options, resources, s.slots))
+		listener, err := net.Listen("tcp", grpcAddr)
+
+		if err != nil {
+			glog.Fatalf("listening on %s failed: %s\n", grpcAddr, err)
+		}
+
+		glog.Infof("streaming on %s\n", grpcAddr)
+
+		go func() {
+			if err := streams.Serve(listener); err != nil {
+				glog.Fatalf("streaming failed: %s\n", err)
+			}
+		}()
+	}
+
+	go func() {
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+		<-interrupts
+		glog.Infoln("shutting down")
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			glog.Errorf("shutting down failed: %s\n", err)
+		}
+
+		if streams != nil {
+			done := make(chan struct{})
+
+			go func() {
+				streams.GracefulStop()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				glog.Errorln("shutting down the streams timed out")
+				streams.Stop()
+			}
+		}
+
+		close(stopped)
+	}()
+
+	glog.Infof("serving on %s\n", addr)
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		glog.Fatalf("serving failed: %s\n", err)
+	}
+
+	<-stopped
+	glog.Flush()
+}
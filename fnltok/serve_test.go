@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/fnl/tokenizer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestService(options tokenizer.Option, maxBytes int64) *httptest.Server {
+	s := &httpService{
+		options:   options,
+		resources: &tokenizer.Resources{},
+		slots:     make(chan struct{}, 2),
+		maxBytes:  maxBytes,
+	}
+
+	return httptest.NewServer(s.handler())
+}
+
+// post a request and decode its JSON response
+func post(t *testing.T, url, contentType, body string, status int, response interface{}) {
+	r, err := http.Post(url, contentType, strings.NewReader(body))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode != status {
+		t.Errorf("POST %s %q: expected status %d, got %d", url, body, status, r.StatusCode)
+	} else if err := json.NewDecoder(r.Body).Decode(response); err != nil {
+		t.Errorf("POST %s %q: decoding the response failed: %s", url, body, err)
+	}
+}
+
+func values(result tokenizeResult) []string {
+	var values []string
+
+	for _, token := range result.Tokens {
+		values = append(values, token.Value)
+	}
+
+	return values
+}
+
+func TestServeText(t *testing.T) {
+	server := newTestService(tokenizer.Lowercase, 100)
+	defer server.Close()
+	var result tokenizeResult
+	post(t, server.URL+"/tokenize", "text/plain", "Hello, World", http.StatusOK, &result)
+
+	if strings.Join(values(result), " ") != "hello , world" {
+		t.Errorf("unexpected tokens %v", result.Tokens)
+	} else if result.Tokens[2].Raw != "World" || result.Tokens[2].Start != 7 || result.Tokens[2].End != 12 {
+		t.Errorf("unexpected token %+v", result.Tokens[2])
+	}
+}
+
+func TestServeOptions(t *testing.T) {
+	server := newTestService(tokenizer.Lowercase, 100)
+	defer server.Close()
+	var result tokenizeResult
+
+	// (replacing the server's options)
+	post(t, server.URL+"/tokenize", "application/json", `{"text": "A &amp; B", "options": ["entities"]}`,
+		http.StatusOK, &result)
+
+	if strings.Join(values(result), " ") != "A & B" {
+		t.Errorf("options: unexpected tokens %v", result.Tokens)
+	}
+
+	post(t, server.URL+"/tokenize?options=spaces,Entities", "text/plain", "A &amp; B", http.StatusOK, &result)
+
+	if strings.Join(values(result), "|") != "A| |&| |B" {
+		t.Errorf("query options: unexpected tokens %v", result.Tokens)
+	}
+
+	var failure map[string]string
+	post(t, server.URL+"/tokenize?options=nonsense", "text/plain", "A", http.StatusBadRequest, &failure)
+
+	if !strings.Contains(failure["error"], "nonsense") {
+		t.Errorf("unexpected error %q", failure["error"])
+	}
+}
+
+func TestServeBatch(t *testing.T) {
+	server := newTestService(tokenizer.NoOptions, 100)
+	defer server.Close()
+	var response map[string][]tokenizeResult
+	post(t, server.URL+"/tokenize", "application/json; charset=utf-8", `{"texts": ["a b", "", "c"]}`,
+		http.StatusOK, &response)
+	results := response["results"]
+
+	if len(results) != 3 || len(results[0].Tokens) != 2 || len(results[1].Tokens) != 0 ||
+		strings.Join(values(results[2]), " ") != "c" {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	var failure map[string]string
+	post(t, server.URL+"/tokenize", "application/json", `{"text": "a", "texts": ["b"]}`,
+		http.StatusBadRequest, &failure)
+	post(t, server.URL+"/tokenize", "application/json", `{"text": `, http.StatusBadRequest, &failure)
+}
+
+func TestServeLimits(t *testing.T) {
+	server := newTestService(tokenizer.NoOptions, 10)
+	defer server.Close()
+	var failure map[string]string
+	post(t, server.URL+"/tokenize", "text/plain", strings.Repeat("a ", 10), http.StatusRequestEntityTooLarge, &failure)
+	r, err := http.Get(server.URL + "/tokenize")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Body.Close()
+
+	if r.StatusCode != http.StatusMethodNotAllowed || r.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("GET: expected status %d allowing POST, got %d allowing %q",
+			http.StatusMethodNotAllowed, r.StatusCode, r.Header.Get("Allow"))
+	}
+}
+
+func TestServeHealth(t *testing.T) {
+	server := newTestService(tokenizer.NoOptions, 10)
+	defer server.Close()
+	r, err := http.Get(server.URL + "/health")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer r.Body.Close()
+	var health struct {
+		Status  string   `json:"status"`
+		Options []string `json:"options"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	} else if r.StatusCode != http.StatusOK || health.Status != "ok" || len(health.Options) != len(tokenizer.OptionNames()) {
+		t.Errorf("unexpected health %d %+v", r.StatusCode, health)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/fnl/tokenizer"
+	"strings"
+	"unicode/utf8"
+)
+
+// true if writing standoff annotations (brat or standoff)
+func standoffFormat() bool {
+	return format == "brat" || format == "standoff"
+}
+
+// lineOffsets splits lines like bufio.ScanLines,
+// while counting the runes before each line in the file
+type lineOffsets struct {
+	offset int // the rune offset of the line scanned last
+	next   int // the rune offset of the line after it
+}
+
+func (o *lineOffsets) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+
+	if token != nil {
+		o.offset = o.next
+		o.next += utf8.RuneCount(data[:advance])
+	}
+
+	return advance, token, err
+}
+
+// standoff formats the tokens of an input line as annotations
+// with rune offsets in the file, one per line:
+// "start\tend\tclass" (-format standoff) or BRAT's
+// "T<id>\tclass start end\traw" (-format brat), numbered after the last id;
+// BRAT annotations skip spaces, linebreaks, and errors;
+// returns the annotations and the last id used
+func standoff(tokens []tokenizer.Token, input line, id int) (string, int) {
+	var result strings.Builder
+
+	for _, token := range tokens {
+		if token.IsEnd() || token.Class == tokenizer.ErrorToken {
+			continue
+		}
+
+		start := input.offset + utf8.RuneCountInString(input.text[:token.Start])
+		end := start + utf8.RuneCountInString(token.Raw)
+
+		if format == "standoff" {
+			fmt.Fprintf(&result, "%d\t%d\t%s\n", start, end, token.ClassName())
+		} else if !token.IsSpace() && !token.IsLinebreak() {
+			id++
+			fmt.Fprintf(&result, "T%d\t%s %d %d\t%s\n", id, token.ClassName(), start, end, token.Raw)
+		}
+	}
+
+	return result.String(), id
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"github.com/fnl/tokenizer"
+	"strings"
+	"testing"
+)
+
+// lex the lines of a file (with spaces) into their standoff annotations
+func lexStandoff(file string) string {
+	var result strings.Builder
+	offsets := &lineOffsets{}
+	scanner := bufio.NewScanner(strings.NewReader(file))
+	scanner.Split(offsets.scanLines)
+	id := 0
+
+	for scanner.Scan() {
+		input := make(chan string, 1)
+		input <- scanner.Text()
+		close(input)
+		var tokens []tokenizer.Token
+
+		for token := range tokenizer.Lex(input, 10, tokenizer.Spaces) {
+			tokens = append(tokens, token)
+		}
+
+		var annotations string
+		annotations, id = standoff(tokens, line{text: scanner.Text(), offset: offsets.offset}, id)
+		result.WriteString(annotations)
+	}
+
+	return result.String()
+}
+
+func TestLineOffsets(t *testing.T) {
+	offsets := &lineOffsets{}
+	scanner := bufio.NewScanner(strings.NewReader("a\r\nbé c\n\nΔ x"))
+	scanner.Split(offsets.scanLines)
+	var lines []string
+	var starts []int
+
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		starts = append(starts, offsets.offset)
+	}
+
+	if strings.Join(lines, "|") != "a|bé c||Δ x" {
+		t.Errorf("unexpected lines %q", lines)
+	} else if len(starts) != 4 || starts[0] != 0 || starts[1] != 3 || starts[2] != 8 || starts[3] != 9 {
+		t.Errorf("expected the rune offsets [0 3 8 9], got %v", starts)
+	}
+}
+
+func TestStandoff(t *testing.T) {
+	defer func(f string) { format = f }(format)
+	file := "α b\r\nçé d\r\n\n€ e"
+
+	for _, test := range []struct {
+		format   string
+		expected string
+	}{
+		{"standoff", "0\t1\tWord\n1\t2\tSpace\n2\t3\tWord\n" +
+			"5\t7\tWord\n7\t8\tSpace\n8\t9\tWord\n" +
+			"12\t13\tSymbol\n13\t14\tSpace\n14\t15\tWord\n"},
+		{"brat", "T1\tWord 0 1\tα\nT2\tWord 2 3\tb\n" +
+			"T3\tWord 5 7\tçé\nT4\tWord 8 9\td\n" +
+			"T5\tSymbol 12 13\t€\nT6\tWord 14 15\te\n"},
+	} {
+		format = test.format
+
+		if result := lexStandoff(file); result != test.expected {
+			t.Errorf("-format %s: expected\n%s\ngot\n%s", test.format, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/fnl/tokenizer"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+	"unicode/utf8"
+)
+
+// a regular expression matching HTML entities
+var entityPattern = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+
+// a word and its count
+type wordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// the statistics of a file (or all files), as written by the stats command
+type statistics struct {
+	File           string         `json:"file,omitempty"`
+	Files          int            `json:"files,omitempty"` // of the total
+	Lines          int            `json:"lines"`
+	Characters     int            `json:"characters"`
+	Tokens         int            `json:"tokens"`
+	TokensPerLine  float64        `json:"tokens_per_line"`
+	Types          int            `json:"types"`
+	TypeTokenRatio float64        `json:"type_token_ratio"`
+	Classes        map[string]int `json:"classes"`
+	Controls       int            `json:"ignored_controls"`
+	ControlRate    float64        `json:"ignored_control_rate"` // per character
+	Entities       int            `json:"entities"`
+	Greek          int            `json:"greek_letters"`
+	Hyphens        int            `json:"hyphens"`
+	TopWords       []wordCount    `json:"top_words"`
+	values         map[string]int // the counts of all token values
+	words          map[string]int // the counts of the words
+}
+
+func newStatistics(file string) *statistics {
+	return &statistics{
+		File:    file,
+		Classes: make(map[string]int),
+		values:  make(map[string]int),
+		words:   make(map[string]int),
+	}
+}
+
+// count the difference in the matches of a function in the raw and normalized token
+func normalized(token tokenizer.Token, count func(string) int) int {
+	if n := count(token.Raw) - count(token.Value); n > 0 {
+		return n
+	}
+
+	return 0
+}
+
+// count the runes in the text that are not ASCII and match
+func countRunes(matches func(rune) bool) func(string) int {
+	return func(text string) int {
+		n := 0
+
+		for _, r := range text {
+			if r >= utf8.RuneSelf && matches(r) {
+				n++
+			}
+		}
+
+		return n
+	}
+}
+
+var countGreek = countRunes(func(r rune) bool { return unicode.Is(unicode.Greek, r) })
+
+// (the hyphens and dashes the lexer maps, including soft hyphens)
+var countDashes = countRunes(tokenizer.IsHyphen)
+
+func countEntities(text string) int {
+	return len(entityPattern.FindAllStringIndex(text, -1))
+}
+
+// count an input line (or the text of a JSON record),
+// and the control characters in it the lexer ignores, if ignoring them
+func (s *statistics) countLine(text string, ignoring bool) {
+	s.Lines++
+	s.Characters += utf8.RuneCountInString(text)
+
+	if ignoring {
+		_, controls := tokenizer.CountInvalid(text)
+		s.Controls += controls
+	}
+}
+
+// count a token
+func (s *statistics) count(token tokenizer.Token) {
+	if token.IsEnd() {
+		return
+	}
+
+	s.Tokens++
+	s.Classes[token.ClassName()]++
+	s.values[token.Value]++
+
+	if token.IsWord() {
+		s.words[token.Value]++
+	}
+
+	s.Entities += normalized(token, countEntities)
+	s.Greek += normalized(token, countGreek)
+	s.Hyphens += normalized(token, countDashes)
+}
+
+// add the counts of another file
+func (s *statistics) add(other *statistics) {
+	s.Files++
+	s.Lines += other.Lines
+	s.Characters += other.Characters
+	s.Tokens += other.Tokens
+	s.Controls += other.Controls
+	s.Entities += other.Entities
+	s.Greek += other.Greek
+	s.Hyphens += other.Hyphens
+
+	for class, n := range other.Classes {
+		s.Classes[class] += n
+	}
+	for value, n := range other.values {
+		s.values[value] += n
+	}
+	for word, n := range other.words {
+		s.words[word] += n
+	}
+}
+
+// compute the ratios and the top words from the counts
+func (s *statistics) summarize(top int) {
+	s.Types = len(s.values)
+
+	if s.Lines > 0 {
+		s.TokensPerLine = float64(s.Tokens) / float64(s.Lines)
+	}
+	if s.Tokens > 0 {
+		s.TypeTokenRatio = float64(s.Types) / float64(s.Tokens)
+	}
+	if s.Characters > 0 {
+		s.ControlRate = float64(s.Controls) / float64(s.Characters)
+	}
+
+	s.TopWords = []wordCount{}
+
+	for word, n := range s.words {
+		s.TopWords = append(s.TopWords, wordCount{word, n})
+	}
+
+	sort.Slice(s.TopWords, func(i, j int) bool {
+		if s.TopWords[i].Count != s.TopWords[j].Count {
+			return s.TopWords[i].Count > s.TopWords[j].Count
+		}
+
+		return s.TopWords[i].Word < s.TopWords[j].Word
+	})
+
+	if len(s.TopWords) > top {
+		s.TopWords = s.TopWords[:top]
+	}
+}
+
+// write the statistics as a table (or a JSON object, with -format jsonl)
+func (s *statistics) write(w io.Writer) {
+	if format == "jsonl" {
+		fmt.Fprintln(w, string(marshal(s)))
+		return
+	}
+
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	if s.File != "" {
+		fmt.Fprintf(table, "file\t%s\n", s.File)
+	} else {
+		fmt.Fprintf(table, "total\t%d files\n", s.Files)
+	}
+
+	fmt.Fprintf(table, "lines\t%d\n", s.Lines)
+	fmt.Fprintf(table, "characters\t%d\n", s.Characters)
+	fmt.Fprintf(table, "tokens\t%d\n", s.Tokens)
+	fmt.Fprintf(table, "tokens/line\t%.2f\n", s.TokensPerLine)
+	fmt.Fprintf(table, "types\t%d\n", s.Types)
+	fmt.Fprintf(table, "type/token ratio\t%.4f\n", s.TypeTokenRatio)
+
+	for class := tokenizer.LinebreakToken; class <= tokenizer.ErrorToken; class++ {
+		token := tokenizer.Token{Class: class}
+
+		if n := s.Classes[token.ClassName()]; n > 0 {
+			fmt.Fprintf(table, "  %s tokens\t%d\n", token.ClassName(), n)
+		}
+	}
+
+	fmt.Fprintf(table, "ignored controls\t%d (%.4f%%)\n", s.Controls, 100*s.ControlRate)
+	fmt.Fprintf(table, "entities\t%d\n", s.Entities)
+	fmt.Fprintf(table, "Greek letters\t%d\n", s.Greek)
+	fmt.Fprintf(table, "hyphens\t%d\n", s.Hyphens)
+
+	var words []string
+
+	for _, word := range s.TopWords {
+		words = append(words, fmt.Sprintf("%s (%d)", word.Word, word.Count))
+	}
+
+	fmt.Fprintf(table, "top words\t%s\n\n", strings.Join(words, ", "))
+	table.Flush()
+}
+
+// collectStatistics tokenizes a file (read like the files to tokenize,
+// see readText) and counts its lines and tokens
+func collectStatistics(path string, options tokenizer.Option, resources *tokenizer.Resources) *statistics {
+	s := newStatistics(path)
+	input := make(chan string, 100)
+	records := make(chan line, 100)
+	lexed := make(chan string, 100)
+	lines := make(chan *statistics)
+	tokens := tokenizer.LexWith(lexed, 100, options, resources)
+	ignoring := options&(tokenizer.EmitInvalid|tokenizer.ReplaceInvalid|tokenizer.FailInvalid) == 0
+
+	go func() {
+		file := openInput(path)
+		readText(path, file, io.Discard, []chan string{input}, []chan line{records})
+		closeStream(path, file)
+		close(input)
+		close(records)
+	}()
+
+	go func() {
+		for range records {
+			// (the JSON records are not needed)
+		}
+	}()
+
+	go func() {
+		counts := newStatistics(path)
+
+		for text := range input {
+			counts.countLine(text, ignoring)
+			lexed <- text
+		}
+
+		close(lexed)
+		lines <- counts
+	}()
+
+	for token := range tokens {
+		s.count(token)
+	}
+
+	counts := <-lines
+	s.Lines = counts.Lines
+	s.Characters = counts.Characters
+	s.Controls = counts.Controls
+	return s
+}
+
+// writeStatistics writes the statistics of each input file and of all of them to w
+func writeStatistics(options tokenizer.Option, resources *tokenizer.Resources, w io.Writer) {
+	paths := flag.Args()
+	total := newStatistics("")
+
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	for _, input := range findInputs(paths) {
+		s := collectStatistics(input.path, options, resources)
+		total.add(s)
+		s.summarize(top)
+		s.write(w)
+	}
+
+	total.summarize(top)
+	total.write(w)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"github.com/fnl/tokenizer"
+	"testing"
+)
+
+func TestCountLine(t *testing.T) {
+	s := newStatistics("file")
+	s.countLine("a\tb\x00ä\x01", true)
+	s.countLine("c\x02", false)
+
+	if s.Lines != 2 || s.Characters != 8 || s.Controls != 2 {
+		t.Errorf("expected 2 lines, 8 characters, and 2 controls, got %d, %d, and %d",
+			s.Lines, s.Characters, s.Controls)
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	s := newStatistics("file")
+
+	for _, token := range []tokenizer.Token{
+		{Class: tokenizer.WordToken, Value: "&", Raw: "&amp;"},
+		{Class: tokenizer.WordToken, Value: "alpha", Raw: "α"},
+		{Class: tokenizer.SymbolToken, Value: "-", Raw: "–"},
+		{Class: tokenizer.WordToken, Value: "alpha", Raw: "alpha"},
+		{Class: tokenizer.EndToken},
+	} {
+		s.count(token)
+	}
+
+	if s.Tokens != 4 || s.Entities != 1 || s.Greek != 1 || s.Hyphens != 1 {
+		t.Errorf("expected 4 tokens, 1 entity, 1 Greek letter, and 1 hyphen, got %d, %d, %d, and %d",
+			s.Tokens, s.Entities, s.Greek, s.Hyphens)
+	}
+	if s.Classes["Word"] != 3 || s.Classes["Symbol"] != 1 || s.Classes["End"] != 0 {
+		t.Errorf("unexpected classes %v", s.Classes)
+	}
+	if s.words["alpha"] != 2 || s.values["-"] != 1 || s.words["-"] != 0 {
+		t.Errorf("unexpected words %v and values %v", s.words, s.values)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	total := newStatistics("")
+
+	for _, text := range []string{"b a b", "c b a"} {
+		s := newStatistics("file")
+		s.countLine(text, true)
+
+		for _, word := range []string{text[0:1], text[2:3], text[4:5]} {
+			s.count(tokenizer.Token{Class: tokenizer.WordToken, Value: word, Raw: word})
+		}
+
+		total.add(s)
+	}
+
+	total.summarize(2)
+	expected := []wordCount{{"b", 3}, {"a", 2}}
+
+	if total.Files != 2 || total.Lines != 2 || total.Tokens != 6 || total.Types != 3 {
+		t.Errorf("expected 2 files, 2 lines, 6 tokens, and 3 types, got %d, %d, %d, and %d",
+			total.Files, total.Lines, total.Tokens, total.Types)
+	}
+	if total.TokensPerLine != 3 || total.TypeTokenRatio != 0.5 {
+		t.Errorf("expected 3 tokens per line and a type/token ratio of 0.5, got %f and %f",
+			total.TokensPerLine, total.TypeTokenRatio)
+	}
+	if fmt.Sprint(total.TopWords) != fmt.Sprint(expected) {
+		t.Errorf("expected the top words %v, got %v", expected, total.TopWords)
+	}
+}
+
+func TestSummarizeNothing(t *testing.T) {
+	s := newStatistics("empty")
+	s.summarize(10)
+
+	if s.TokensPerLine != 0 || s.TypeTokenRatio != 0 || s.ControlRate != 0 || s.TopWords == nil {
+		t.Errorf("unexpected summary of no lines %+v", s)
+	}
+}
+
+func TestCountHyphens(t *testing.T) {
+	s := newStatistics("file")
+	input := make(chan string, 1)
+	input <- "infor\u00ADmation in\u00AD \u2212 1 \u2014 e-mail"
+	close(input)
+
+	for token := range tokenizer.Lex(input, 10, tokenizer.Hyphens) {
+		s.count(token)
+	}
+
+	if s.Hyphens != 4 {
+		t.Errorf("expected 4 (soft) hyphens, got %d", s.Hyphens)
+	}
+}
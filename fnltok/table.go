@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"github.com/golang/glog"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// the indexes of the -columns to tokenize (all if empty)
+var selectedColumns []int
+
+// true if tokenizing the fields of table rows one by one
+// (with -csv, or -tsv and -columns)
+func tableMode() bool {
+	return csvMode || tsv && len(selectedColumns) > 0
+}
+
+// parseColumns parses a comma-separated list of column numbers (from 1),
+// dropping repeated columns (that would be tokenized twice)
+func parseColumns(list string) []int {
+	var indexes []int
+	seen := make(map[int]bool)
+
+	for _, column := range strings.Split(list, ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(column))
+
+		if err != nil || number < 1 {
+			glog.Fatalf("invalid -columns number %q\n", column)
+		}
+
+		if !seen[number] {
+			seen[number] = true
+			indexes = append(indexes, number-1)
+		}
+	}
+
+	return indexes
+}
+
+// a row of a table, with the fields to tokenize
+type row struct {
+	fields   []string // the fields of the row
+	selected []int    // the indexes of the fields to tokenize
+}
+
+// newRow selects the -columns the row has (or all of its fields)
+func newRow(fields []string) *row {
+	r := &row{fields: fields}
+
+	if len(selectedColumns) == 0 {
+		for i := range fields {
+			r.selected = append(r.selected, i)
+		}
+	} else {
+		for _, i := range selectedColumns {
+			if i < len(fields) {
+				r.selected = append(r.selected, i)
+			}
+		}
+	}
+
+	return r
+}
+
+// the row, formatted as a CSV record (without the newline) or as TSV
+func (r *row) String() string {
+	if !csvMode {
+		return strings.Join(r.fields, "\t")
+	}
+
+	var result strings.Builder
+	writer := csv.NewWriter(&result)
+	// writing to a strings.Builder never fails:
+	_ = writer.Write(r.fields)
+	writer.Flush()
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+// tableRows reassembles the rows from the results of their selected fields
+type tableRows struct {
+	records chan line // the rows, in the order their fields are tokenized
+	current *row      // the row of the fields being tokenized
+	field   int       // the number of its fields tokenized
+}
+
+// add sets the next selected field of the current row to the result;
+// returns the row once all of its selected fields are set
+func (t *tableRows) add(result string) (string, bool) {
+	if t.current == nil {
+		t.current = (<-t.records).row
+		t.field = 0
+	}
+
+	// (a row without selected fields was sent as a single, empty input)
+	if t.field < len(t.current.selected) {
+		t.current.fields[t.current.selected[t.field]] = result
+	}
+
+	if t.field++; t.field < len(t.current.selected) {
+		return "", false
+	}
+
+	result = t.current.String()
+	t.current = nil
+	return result, true
+}
+
+// readTable sends the selected fields of each row to the lexers in turn,
+// and the rows themselves along to convertTokens;
+// writes a -header row to w as it is
+func readTable(name string, file io.Reader, w io.Writer, inputs []chan string, records []chan line) {
+	var next func() ([]string, error)
+
+	if csvMode {
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		next = reader.Read
+	} else {
+		scanner := bufio.NewScanner(file)
+		next = func() ([]string, error) {
+			if scanner.Scan() {
+				return strings.Split(scanner.Text(), "\t"), nil
+			} else if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+
+			return nil, io.EOF
+		}
+	}
+
+	if header {
+		if fields, err := next(); err == nil {
+			fmt.Fprintln(w, newRow(fields))
+		} else if err != io.EOF {
+			glog.Fatalf("reading %q failed: %s\n", name, err)
+		}
+	}
+
+	for i := 0; ; i++ {
+		fields, err := next()
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			glog.Fatalf("reading %q failed: %s\n", name, err)
+		}
+
+		r := newRow(fields)
+		n := i % len(inputs)
+		records[n] <- line{row: r}
+
+		if len(r.selected) == 0 {
+			inputs[n] <- ""
+		}
+
+		for _, field := range r.selected {
+			inputs[n] <- fields[field]
+		}
+	}
+}
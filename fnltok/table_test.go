@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// read a table, "tokenize" its selected fields by uppercasing them,
+// and reassemble its rows
+func upcaseTable(table string) string {
+	var result strings.Builder
+	inputs := make(chan string, 100)
+	records := make(chan line, 100)
+	readTable("table", strings.NewReader(table), &result, []chan string{inputs}, []chan line{records})
+	close(inputs)
+	rows := &tableRows{records: records}
+
+	for field := range inputs {
+		if row, ok := rows.add(strings.ToUpper(field)); ok {
+			fmt.Fprintln(&result, row)
+		}
+	}
+
+	return result.String()
+}
+
+func TestParseColumns(t *testing.T) {
+	if columns := parseColumns("3, 1,3,2,1"); fmt.Sprint(columns) != "[2 0 1]" {
+		t.Errorf("expected the columns [2 0 1], got %v", columns)
+	}
+}
+
+func TestTableRows(t *testing.T) {
+	defer func(c, t, h bool, s []int) {
+		csvMode, tsv, header, selectedColumns = c, t, h, s
+	}(csvMode, tsv, header, selectedColumns)
+
+	for _, test := range []struct {
+		csv      bool
+		header   bool
+		columns  string
+		table    string
+		expected string
+	}{
+		{false, false, "", "a\tb\nc\n", "A\tB\nC\n"},
+		{false, false, "3,1", "a\tb\tc\nd\te\nf\n\n", "A\tb\tC\nD\te\nF\n\n"},
+		{false, false, "2", "a\tb\nc\nd\te\n", "a\tB\nc\nd\tE\n"},
+		{false, false, "2,2", "a\tb\n", "a\tB\n"},
+		{false, true, "2", "x\ty\na\tb\n", "x\ty\na\tB\n"},
+		{false, true, "", "", ""},
+		{true, false, "", "a,\"b, c\"\n\"d\ne\",f\n", "A,\"B, C\"\n\"D\nE\",F\n"},
+		{true, false, "2", "a,\"b\nc\",d\ne\n", "a,\"B\nC\",d\ne\n"},
+		{true, true, "1", "\"x,y\",z\na,b\n", "\"x,y\",z\nA,b\n"},
+	} {
+		csvMode, tsv, header, selectedColumns = test.csv, !test.csv, test.header, nil
+
+		if test.columns != "" {
+			selectedColumns = parseColumns(test.columns)
+		}
+
+		if result := upcaseTable(test.table); result != test.expected {
+			t.Errorf("csv=%t header=%t columns=%q %q: expected %q, got %q",
+				test.csv, test.header, test.columns, test.table, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"github.com/fnl/tokenizer"
+	"github.com/golang/glog"
+	"io"
+)
+
+// trainTruecaser learns a truecasing model from the input and writes it to w
+func trainTruecaser(options tokenizer.Option, w io.Writer) {
+	input := make(chan string, 100)
+	model := make(chan *tokenizer.Truecaser)
+	tokens := tokenizer.Lex(input, 100, options&^(tokenizer.Lowercase|tokenizer.Truecase))
+
+	go func() {
+		model <- tokenizer.TrainTruecaser(tokens)
+	}()
+
+	readLines(input)
+	close(input)
+
+	if _, err := (<-model).WriteTo(w); err != nil {
+		glog.Fatalf("writing truecasing model failed: %s\n", err)
+	}
+}
+
+// readLines sends all lines of the input files (or STDIN) to the channel
+func readLines(input chan string) {
+	paths := flag.Args()
+
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	for _, path := range paths {
+		file := openInput(path)
+		scanner := bufio.NewScanner(file)
+
+		for scanner.Scan() {
+			input <- scanner.Text()
+		}
+
+		if err := scanner.Err(); err != nil {
+			glog.Fatalf("reading %q failed: %s\n", path, err)
+		}
+
+		closeStream(path, file)
+	}
+}
@@ -0,0 +1,205 @@
+//go:build ignore
+
+// Generates wordbreak_tables.go from the Unicode Character Database:
+//
+//	go run gen_wordbreak.go [-ucd URL|DIR] [-version VERSION]
+//
+// The Format property is merged into Extend,
+// as the word break rules treat them alike.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ucd string
+var version string
+
+// the Word_Break property values and their constant names
+var properties = map[string]string{
+	"CR":                 "wbCR",
+	"LF":                 "wbLF",
+	"Newline":            "wbNewline",
+	"Extend":             "wbExtend",
+	"Format":             "wbExtend",
+	"ZWJ":                "wbZWJ",
+	"Regional_Indicator": "wbRegionalIndicator",
+	"Katakana":           "wbKatakana",
+	"Hebrew_Letter":      "wbHebrewLetter",
+	"ALetter":            "wbALetter",
+	"Single_Quote":       "wbSingleQuote",
+	"Double_Quote":       "wbDoubleQuote",
+	"MidNumLet":          "wbMidNumLet",
+	"MidLetter":          "wbMidLetter",
+	"MidNum":             "wbMidNum",
+	"Numeric":            "wbNumeric",
+	"ExtendNumLet":       "wbExtendNumLet",
+	"WSegSpace":          "wbWSegSpace",
+}
+
+type span struct {
+	lo, hi rune
+	value  string
+}
+
+func main() {
+	flag.StringVar(&version, "version", "17.0.0", "the Unicode version")
+	flag.StringVar(&ucd, "ucd", "", "the UCD base URL or directory (default: unicode.org)")
+	flag.Parse()
+
+	if ucd == "" {
+		ucd = "https://www.unicode.org/Public/" + version + "/ucd"
+	}
+
+	wordBreaks := parse("auxiliary/WordBreakProperty.txt", func(value string) string {
+		return properties[value]
+	})
+	pictographs := parse("emoji/emoji-data.txt", func(value string) string {
+		if value == "Extended_Pictographic" {
+			return value
+		}
+		return ""
+	})
+
+	out, err := os.Create("wordbreak_tables.go")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, "// Code generated by gen_wordbreak.go; DO NOT EDIT.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "package tokenizer")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// Word_Break property ranges (Unicode %s)\n", version)
+	fmt.Fprintln(w, "var wordBreakRanges = []wordBreakRange{")
+
+	for _, s := range wordBreaks {
+		fmt.Fprintf(w, "\t{0x%04X, 0x%04X, %s},\n", s.lo, s.hi, s.value)
+	}
+
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// Extended_Pictographic ranges (Unicode %s)\n", version)
+	fmt.Fprintln(w, "var extendedPictographicRanges = [][2]rune{")
+
+	for _, s := range pictographs {
+		fmt.Fprintf(w, "\t{0x%04X, 0x%04X},\n", s.lo, s.hi)
+	}
+
+	fmt.Fprintln(w, "}")
+
+	if err = w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// open a UCD file, either remote or local
+func open(name string) io.ReadCloser {
+	if strings.HasPrefix(ucd, "http://") || strings.HasPrefix(ucd, "https://") {
+		resp, err := http.Get(ucd + "/" + name)
+
+		if err != nil {
+			log.Fatal(err)
+		} else if resp.StatusCode != http.StatusOK {
+			log.Fatalf("fetching %s failed: %s", name, resp.Status)
+		}
+
+		return resp.Body
+	}
+
+	file, err := os.Open(filepath.Join(ucd, name))
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return file
+}
+
+// parse the ranges of a UCD property file,
+// mapping each property value with the given function;
+// skips values mapped to the empty string
+// and merges adjacent ranges with the same value
+func parse(name string, mapping func(string) string) []span {
+	file := open(name)
+	defer file.Close()
+	var spans []span
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if i := strings.IndexByte(line, '#'); i != -1 {
+			line = line[:i]
+		}
+
+		fields := strings.Split(line, ";")
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		value := mapping(strings.TrimSpace(fields[1]))
+
+		if value == "" {
+			continue
+		}
+
+		bounds := strings.Split(strings.TrimSpace(fields[0]), "..")
+		lo := codePoint(bounds[0])
+		hi := lo
+
+		if len(bounds) == 2 {
+			hi = codePoint(bounds[1])
+		}
+
+		spans = append(spans, span{lo, hi, value})
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	return merge(spans)
+}
+
+// merge sorts the spans and joins adjacent ones with the same value
+func merge(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].lo < spans[j].lo })
+
+	var merged []span
+
+	for _, s := range spans {
+		if n := len(merged); n > 0 && merged[n-1].hi+1 == s.lo && merged[n-1].value == s.value {
+			merged[n-1].hi = s.hi
+		} else {
+			merged = append(merged, s)
+		}
+	}
+
+	return merged
+}
+
+// parse a hexadecimal code point
+func codePoint(hex string) rune {
+	cp, err := strconv.ParseUint(hex, 16, 32)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return rune(cp)
+}
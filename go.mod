@@ -0,0 +1,20 @@
+module github.com/fnl/tokenizer
+
+go 1.25.0
+
+require (
+	github.com/dsnet/compress v0.0.1
+	github.com/golang/glog v1.2.5
+	github.com/klauspost/compress v1.20.1
+	github.com/klauspost/pgzip v1.2.7
+	github.com/ulikunitz/xz v0.5.17
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+)
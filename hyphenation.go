@@ -0,0 +1,153 @@
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// a regular expression matching a linebreak and the spaces around it
+var linebreakHyphen = regexp.MustCompile(`^[ \t]*(?:\r\n|[\n\v\f\r\x{85}\x{2028}\x{2029}])[ \t]*`)
+
+// true if this lexer splits hyphenated words
+func (l *lexer) splitsHyphenated() bool {
+	if l.options&LexiconHyphenated != 0 {
+		return l.resources.Lexicon != nil
+	}
+
+	return l.options&(SplitHyphenated|AttachHyphenated) != 0
+}
+
+// true if the word is in the lexicon (in its case or lowercased)
+func (l *lexer) inLexicon(word string) bool {
+	return l.resources.Lexicon.Contains(word) ||
+		l.resources.Lexicon.Contains(strings.ToLower(word))
+}
+
+// emitHyphenated emits the scanned hyphenated word
+// as its parts (words or numbers) and hyphens,
+// as the SplitHyphenated, AttachHyphenated, and LexiconHyphenated options require
+func (l *lexer) emitHyphenated() {
+	parts := strings.Split(l.buffer[l.start:l.pos], "-")
+
+	if l.options&LexiconHyphenated != 0 {
+		for _, part := range parts {
+			if !l.inLexicon(part) {
+				l.emit(WordToken)
+				return
+			}
+		}
+	}
+
+	for i, part := range parts {
+		l.pos = l.start + len(part)
+		class := WordToken
+
+		if isNumeric(part) {
+			class = NumberToken
+		}
+
+		if i == len(parts)-1 {
+			l.emit(class)
+		} else if l.options&AttachHyphenated != 0 {
+			l.pos++
+			l.emit(WordToken) // ("19-" is no number)
+		} else {
+			l.emit(class)
+			l.pos++
+			l.emit(SymbolToken)
+		}
+	}
+}
+
+// true if the part of a hyphenated word is a number ("19", "3.5")
+func isNumeric(part string) bool {
+	return unicode.IsDigit(firstRune(part)) && strings.IndexFunc(part, unicode.IsLetter) == -1
+}
+
+// joinLinebreak joins a word broken at a hyphen and a linebreak
+// ("infor-\nmation"), just after scanning the hyphen;
+// drops the hyphen if the joined word is in the Lexicon,
+// or if there is no Lexicon and the word continues in lowercase;
+// returns false if no word continues after the linebreak
+func (l *lexer) joinLinebreak() bool {
+	head, _ := utf8.DecodeLastRuneInString(l.buffer[l.start : l.pos-1])
+	linebreak := linebreakHyphen.FindString(l.buffer[l.pos:])
+	tail := l.buffer[l.pos+len(linebreak):]
+
+	if linebreak == "" || !unicode.IsLetter(head) {
+		return false
+	} else if r, _ := utf8.DecodeRuneInString(tail); !unicode.IsLetter(r) {
+		return false
+	}
+
+	if l.joinsHyphenated(l.buffer[l.start:l.pos-1], tail) {
+		l.replace(l.pos-1, l.pos+len(linebreak), "")
+		l.pos--
+	} else {
+		l.replace(l.pos, l.pos+len(linebreak), "")
+	}
+
+	l.width = 0
+	return true
+}
+
+// joinNextInput joins a word broken at a hyphen at the end of the input
+// with the word the next input starts with ("infor-" and "mation"),
+// just after scanning the hyphen;
+// the joined word is the last token of this input (with the Raw value "infor-"),
+// and the next input is lexed after the end of the word;
+// drops the hyphen like joinLinebreak;
+// returns false if the input goes on after the hyphen,
+// or if the next input does not start with a word (or there is none)
+func (l *lexer) joinNextInput() bool {
+	head, _ := utf8.DecodeLastRuneInString(l.buffer[l.start : l.pos-1])
+
+	if !unicode.IsLetter(head) || strings.TrimLeftFunc(l.buffer[l.pos:], isSpace) != "" || !l.peekInput() {
+		return false
+	}
+
+	indent := len(l.next) - len(strings.TrimLeftFunc(l.next, isSpace))
+	tail := l.next[indent:]
+
+	if end := strings.IndexFunc(tail, func(r rune) bool { return !unicode.IsLetter(r) }); end != -1 {
+		tail = tail[:end]
+	}
+
+	if tail == "" || l.failsOnInvalid() && indexInvalid(l.next) != -1 {
+		return false
+	}
+
+	if l.joinsHyphenated(l.buffer[l.start:l.pos-1], tail) {
+		l.replace(l.pos-1, l.pos, tail)
+		l.pos += len(tail) - 1
+	} else {
+		l.replace(l.pos, l.pos, tail)
+		l.pos += len(tail)
+	}
+
+	l.width = 0
+	l.skip = indent + len(tail)
+	return true
+}
+
+// true if a word broken at a hyphen should be joined without the hyphen
+func (l *lexer) joinsHyphenated(head, tail string) bool {
+	if i := strings.LastIndexByte(head, '-'); i != -1 {
+		head = head[i+1:] // the last part of a compound
+	}
+
+	end := strings.IndexFunc(tail, func(r rune) bool { return !unicode.IsLetter(r) })
+
+	if end != -1 {
+		tail = tail[:end]
+	}
+
+	if l.resources.Lexicon != nil {
+		return l.inLexicon(head + tail)
+	}
+
+	r, _ := utf8.DecodeRuneInString(tail)
+	return unicode.IsLower(r)
+}
@@ -0,0 +1,110 @@
+package tokenizer
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Without the EmitInvalid, ReplaceInvalid, or FailInvalid option,
+// the lexer drops control characters (other than tabs and EOLMarkers)
+// and lexes invalid UTF-8 bytes as symbols;
+// if several of them are set, FailInvalid wins over ReplaceInvalid,
+// and ReplaceInvalid over EmitInvalid.
+
+// true if this lexer emits InvalidTokens
+func (l *lexer) emitsInvalid() bool {
+	return l.options&(EmitInvalid|ReplaceInvalid|FailInvalid) == EmitInvalid
+}
+
+// true if this lexer replaces invalid bytes and control characters
+func (l *lexer) replacesInvalid() bool {
+	return l.options&(ReplaceInvalid|FailInvalid) == ReplaceInvalid
+}
+
+// true if this lexer fails inputs with invalid bytes or control characters
+func (l *lexer) failsOnInvalid() bool {
+	return l.options&FailInvalid != 0
+}
+
+// CountInvalid counts the invalid UTF-8 bytes and the control characters
+// (other than tabs and EOLMarkers) in the text,
+// as the EmitInvalid, ReplaceInvalid, and FailInvalid options find them.
+func CountInvalid(text string) (bytes, controls int) {
+	for i := 0; i < len(text); {
+		r, w := utf8.DecodeRuneInString(text[i:])
+
+		if r == utf8.RuneError && w == 1 {
+			bytes++
+		} else if isControl(r) {
+			controls++
+		}
+
+		i += w
+	}
+
+	return bytes, controls
+}
+
+// true if the rune is a control character, but not a tab or EOL marker
+func isControl(r rune) bool {
+	return unicode.IsControl(r) && r != '\t' && !isEOL(r)
+}
+
+// invalidWidth returns the length of the invalid byte
+// or control character at the start of the text,
+// or zero if the text does not start with one
+func invalidWidth(text string) int {
+	r, w := utf8.DecodeRuneInString(text)
+
+	if r == utf8.RuneError && w == 1 || isControl(r) {
+		return w
+	}
+
+	return 0
+}
+
+// indexInvalid returns the offset of the first invalid byte
+// or control character in the text, or -1 if there is none
+func indexInvalid(text string) int {
+	for i := 0; i < len(text); {
+		if invalidWidth(text[i:]) != 0 {
+			return i
+		}
+
+		_, w := utf8.DecodeRuneInString(text[i:])
+		i += w
+	}
+
+	return -1
+}
+
+// describeInvalid explains the invalid byte or control character
+// at the start of the text
+func describeInvalid(text string, offset int) string {
+	r, w := utf8.DecodeRuneInString(text)
+
+	if r == utf8.RuneError && w == 1 {
+		return fmt.Sprintf("invalid UTF-8 byte %#x at offset %d", text[0], offset)
+	}
+
+	return fmt.Sprintf("control character %U at offset %d", r, offset)
+}
+
+// lexFailure emits an ErrorToken describing the first invalid byte
+// or control character of the input, and skips all of the input
+func lexFailure(l *lexer) stateFn {
+	at := indexInvalid(l.buffer)
+	w := invalidWidth(l.buffer[at:])
+	l.output <- Token{
+		Class: ErrorToken,
+		Value: describeInvalid(l.buffer[at:], at),
+		Raw:   l.buffer[at : at+w],
+		Start: at,
+		End:   at + w,
+	}
+	l.pos = len(l.buffer)
+	l.start = l.pos
+	l.width = 0
+	return lexEnd
+}
@@ -79,14 +79,39 @@ import (
 // and the state of the scanner.
 type lexer struct {
 	name   string     // an ID for this lexer (for logging)
+	raw    string     // the current input, as received
 	buffer string     // the current buffer being scanned
+	edits  []edit     // replacements made on the buffer
 	start  int        // start position of the current token
 	pos    int        // position of the scanner on the buffer
 	width  int        // width of last rune scanned on the buffer before the current position
+	greek  bool       // true if a Greek letter was expanded in the current token
+	shapes shaper     // the shape of the current token (if annotating shapes)
+	script string     // the script of the current word (if splitting scripts)
 	output chan Token // Token output channel
+	// truecasing state:
+	sentenceStart bool // true if no word or number was emitted since the last sentence terminal
+	headline      bool // true if the current input is an all-caps headline
+	// markdown state:
+	fence    *regexp.Regexp // closing fence of the current code block
+	emphasis string         // the emphasis delimiters opened in the current input
+	// markup state:
+	markup markupState // the markup that continues in the next input
+	// dehyphenation state:
+	next   string // the next input, if received ahead of time
+	peeked bool   // true if the next input was received ahead of time
+	skip   int    // the length of the word the next input starts with, if joined
 	// user settings:
-	input   chan string // string input channel
-	options Option      // lexer options (Spaces, Entities, etc.)
+	input     chan string // string input channel
+	options   Option      // lexer options (Spaces, Entities, etc.)
+	resources *Resources  // models used by some options (Truecase, etc.)
+}
+
+// An edit records the replacement of a part of the buffer.
+type edit struct {
+	at  int // buffer position of the replacement
+	old int // length of the replaced string
+	new int // length of the replacement
 }
 
 // The scanner's states are encoded as state functions
@@ -108,14 +133,63 @@ const (
 	NoOptions         = Option(0)   // use no options
 )
 
+// lexer modes change what the lexer considers to be a token
+// or add annotations to the tokens;
+// they are not part of AllOptions and have to be set explicitly
+const (
+	Bio               Option = (AllOptions + 1) << iota // keep biomedical names intact
+	Shapes                                              // annotate word shapes and features
+	Truecase                                            // truecase words (instead of Lowercase)
+	Markup                                              // strip HTML/XML markup
+	Tags                                                // emit HTML/XML tags (implies Markup)
+	Markdown                                            // strip Markdown syntax
+	Social                                              // lex hashtags, mentions, and emoticons
+	Elongations                                         // shorten elongated words ("sooooo")
+	Segment                                             // segment Han, kana, and Thai runs
+	Scripts                                             // split words at script changes
+	UAX29                                               // segment at Unicode word boundaries
+	Abbrevs                                             // keep the period of abbreviations
+	Dehyphenate                                         // join words broken by a hyphen and a linebreak
+	OCR                                                 // clean up OCR and PDF text (implies Dehyphenate)
+	SplitHyphenated                                     // split hyphenated words into their parts and "-" symbols
+	AttachHyphenated                                    // split them, attaching the hyphens to the parts before them
+	LexiconHyphenated                                   // split them only if all parts are in the Lexicon
+	EmitInvalid                                         // emit invalid UTF-8 and control characters as InvalidTokens
+	ReplaceInvalid                                      // replace them with U+FFFD
+	FailInvalid                                         // emit an ErrorToken instead of the tokens of inputs with them
+)
+
+// Resources holds the models some options depend on.
+type Resources struct {
+	Truecaser *Truecaser  // the model used by the Truecase option
+	CJK       *Dictionary // Chinese and Japanese words for the Segment option
+	Thai      *Dictionary // Thai words for the Segment option
+
+	// the abbreviations used by the Abbrevs option
+	// (English abbreviations if nil)
+	Abbreviations *Abbreviations
+
+	// the known words for the LexiconHyphenated, Dehyphenate, and OCR options
+	Lexicon *Dictionary
+}
+
 // all end-of-line runes that give rise to linebreak tokens
 const EOLMarkers string = "\n\v\f\r\u0085\u2028\u2029"
 
 // a regular expression to check if
-// a string might start with an escaped HTML entity;
+// a string might start with an escaped HTML entity,
+// named or numeric (decimal or hexadecimal);
 // does not guarantee its a valid entity -
 // only signals that the string could encode an entity
-var entity = regexp.MustCompile("^&\\w+;")
+var entity = regexp.MustCompile("^&(?:\\w+|#[0-9]+|#[xX][0-9a-fA-F]+);")
+
+// a regular expression to check if
+// a string starts with a stereochemical prefix
+// like "(R)-", "(+)-", or "(2S,3R)-"
+var stereoPrefix = regexp.MustCompile(`^\((?:[+±-]|[0-9]*[RSEZDL](?:,[0-9]*[RSEZ])*)\)-[\pL\pN]`)
+
+// primes used to mark nucleotide or atom positions ("5'-UTR", "N,N'")
+const primes string = "'’′"
 
 // mapping of single to double quote runes
 var normalQuote = map[rune]string{
@@ -145,6 +219,12 @@ var mapHyphen = map[rune]rune{
 // collection of Unicode hyphens and dashes (except ASCII hyphen-minus)
 const hyphens string = "\u00AD\u05BE\u2010\u2011\u2012\u2013\u2014\u2015\u2212\uFE58\uFE63\uFF0D"
 
+// IsHyphen is true for the Unicode hyphens and dashes
+// the Hyphens option maps to the ASCII hyphen-minus.
+func IsHyphen(r rune) bool {
+	return strings.ContainsRune(hyphens, r)
+}
+
 // mapping of Greek letters to Latin names
 var greekLetter = map[rune]string{
 	'\u0391': "Alpha",
@@ -218,7 +298,7 @@ var greekLetter = map[rune]string{
 //   Linebreak:
 //     emit tokens containing EOLMarkers.
 //   Entities:
-//     resolve and replace HTML entities (/&\w+;/).
+//     resolve and replace HTML entities (/&\w+;/, /&#\d+;/, /&#x[\dA-F]+;/).
 //   Quotes:
 //     replace two single with one double quote and
 //     U+02BC (modifier apostrophe) with U+0027 ("'" - apostrophe).
@@ -228,16 +308,116 @@ var greekLetter = map[rune]string{
 //     expand Greek letters to Latin names (Alpha, Beta, ...).
 //   Hyphens:
 //     map various Unicode hyphens to the ASCII hyphen-minus.
+//   Bio:
+//     keep gene, protein, and chemical names intact
+//     ("Ca2+", "5'-UTR", "N,N-dimethyl", "(R)-2-butanol").
+//   Shapes:
+//     annotate tokens with their word shape and orthographic features.
+//   Truecase:
+//     restore the true case of sentence-initial words and
+//     of words in all-caps headlines (but of no others),
+//     using the Truecaser model from the lexer's Resources
+//     (see LexWith); overrides Lowercase.
+//   Markup:
+//     skip HTML/XML tags and comments, drop script and style contents,
+//     and emit block-level tags (p, div, br, ...) as linebreaks;
+//     tags, comments, and script and style elements may span several inputs.
+//   Tags:
+//     like Markup, but emit all tags as TagTokens instead
+//     (a tag that spans several inputs as a TagToken per input).
+//   Markdown:
+//     drop Markdown syntax (headings, emphasis, list markers, ...),
+//     emit code spans and fenced code blocks as CodeTokens,
+//     and link targets as URLTokens;
+//     fenced code blocks may span several inputs.
+//   Social:
+//     emit "#hashtags", "@mentions", "$CASHTAGS", and emoticons
+//     (":-)", "<3", "^_^", ...) as single tokens of their own class;
+//     emoticons must start the input or follow a space.
+//   Elongations:
+//     shorten runs of more than three identical letters
+//     in words and hashtags to three ("sooooo" -> "sooo").
+//   Segment:
+//     split words at runs of Han ideographs, Japanese kana, or Thai,
+//     and segment those runs by longest match
+//     using the CJK or Thai Dictionary from the lexer's Resources;
+//     without a dictionary, Han ideographs are single words,
+//     kana runs of one script form a word,
+//     and Thai runs are not segmented.
+//   Scripts:
+//     split words where their Unicode script changes
+//     (except between Greek and Latin if Greek is expanded),
+//     and annotate tokens with their dominant script.
+//   UAX29:
+//     segment the input at the Unicode word boundaries (UAX #29),
+//     like ICU's or Lucene's StandardTokenizer,
+//     and classify the segments as words, numbers, symbols, spaces,
+//     or linebreaks; all other options and modes are ignored,
+//     except Spaces, Linebreaks, Lowercase, Truecase,
+//     Elongations, Shapes, and Scripts.
+//   Abbrevs:
+//     keep the trailing period of known abbreviations ("Dr.", "etc.")
+//     and initials ("J.", "U.S.", "e.g.") as part of the word,
+//     using the Abbreviations from the lexer's Resources
+//     (or the built-in English ones, if there are none).
+//   Dehyphenate:
+//     join words broken by a hyphen at the end of a line
+//     ("infor-\nmation" -> "information"), as in OCR and PDF text,
+//     also across inputs (the lexer then waits for the next input
+//     before it emits the joined word as the last token of the input);
+//     the hyphen is dropped if the joined word is in the Lexicon
+//     from the lexer's Resources or, without a Lexicon,
+//     if the word continues in lowercase.
+//   OCR:
+//     clean up text from OCR and PDF extraction like Dehyphenate,
+//     and resolve ligatures ("ﬁ" -> "fi"),
+//     drop soft hyphens inside words (but Dehyphenate them at line ends),
+//     join doubled quotes (",," -> "„", "``" -> "“", "''" -> "”"),
+//     and join words split by spaces ("infor mation")
+//     if the Lexicon from the lexer's Resources has the joined word,
+//     but not both of its parts.
+//   SplitHyphenated:
+//     split hyphenated words into their parts and hyphens
+//     ("state", "-", "of", "-", "the", "-", "art"; numeric parts are numbers).
+//   AttachHyphenated:
+//     split hyphenated words, attaching the hyphens
+//     to the parts before them ("state-", "of-", "the-", "art").
+//   LexiconHyphenated:
+//     split hyphenated words only if all their parts are in the Lexicon
+//     from the lexer's Resources (like SplitHyphenated,
+//     or like AttachHyphenated if combined with it).
+//   EmitInvalid:
+//     emit runs of invalid UTF-8 bytes and control characters
+//     (other than tabs and EOLMarkers) as InvalidTokens with their raw bytes,
+//     instead of dropping control characters and lexing invalid bytes as symbols.
+//   ReplaceInvalid:
+//     replace each of them with U+FFFD (emitted as a SymbolToken);
+//     overrides EmitInvalid.
+//   FailInvalid:
+//     emit an ErrorToken for the first of them instead of the input's tokens
+//     (but still its EndToken); overrides EmitInvalid and ReplaceInvalid.
 //   NoOptions:
 //     use none of the options (the zero value default).
 //   AllOptions:
-//     use all of the options.
+//     use all of the options (but none of the modes, like Bio).
 func Lex(input chan string, outputBufferSize int, options Option) chan Token {
+	return LexWith(input, outputBufferSize, options, nil)
+}
+
+// LexWith starts a scanner process just like Lex,
+// but provides the resources (models) that some options depend on.
+// Options that lack their resources are ignored.
+func LexWith(input chan string, outputBufferSize int, options Option, resources *Resources) chan Token {
+	if resources == nil {
+		resources = &Resources{}
+	}
+
 	l := &lexer{
-		name:    fmt.Sprintf("lexer-%04d", rand.Intn(1e4)),
-		options: options,
-		input:   input,
-		output:  make(chan Token, outputBufferSize),
+		name:      fmt.Sprintf("lexer-%04d", rand.Intn(1e4)),
+		options:   options,
+		resources: resources,
+		input:     input,
+		output:    make(chan Token, outputBufferSize),
 	}
 	go l.run() // concurrently runs the scanner
 	return l.output
@@ -297,47 +477,113 @@ func (l *lexer) mapsHyphens() bool {
 	return l.options&Hyphens != 0
 }
 
+// true if this lexer keeps biomedical names intact
+func (l *lexer) keepsBioNames() bool {
+	return l.options&Bio != 0
+}
+
+// true if this lexer annotates word shapes and features
+func (l *lexer) annotatesShapes() bool {
+	return l.options&Shapes != 0
+}
+
+// true if this lexer strips (or emits) markup
+func (l *lexer) stripsMarkup() bool {
+	return l.options&(Markup|Tags) != 0
+}
+
+// true if this lexer emits tags
+func (l *lexer) emitsTags() bool {
+	return l.options&Tags != 0
+}
+
+// true if this lexer reads Markdown
+func (l *lexer) readsMarkdown() bool {
+	return l.options&Markdown != 0
+}
+
+// true if this lexer lexes hashtags, mentions, and emoticons
+func (l *lexer) readsSocial() bool {
+	return l.options&Social != 0
+}
+
+// true if this lexer shortens elongated words
+func (l *lexer) shortensElongations() bool {
+	return l.options&Elongations != 0
+}
+
+// true if this lexer segments Han, kana, and Thai runs
+func (l *lexer) segmentsScripts() bool {
+	return l.options&Segment != 0
+}
+
+// true if this lexer splits words at script changes
+func (l *lexer) splitsScripts() bool {
+	return l.options&Scripts != 0
+}
+
+// true if this lexer segments the input at Unicode word boundaries
+func (l *lexer) segmentsWords() bool {
+	return l.options&UAX29 != 0
+}
+
+// true if this lexer keeps the period of abbreviations
+func (l *lexer) keepsAbbreviations() bool {
+	return l.options&Abbrevs != 0
+}
+
+// true if this lexer joins words broken by a hyphen and a linebreak
+func (l *lexer) dehyphenates() bool {
+	return l.options&(Dehyphenate|OCR) != 0
+}
+
+// true if this lexer cleans up OCR and PDF text
+func (l *lexer) cleansOCR() bool {
+	return l.options&OCR != 0
+}
+
+// true if this lexer truecases words (and has a model to do so)
+func (l *lexer) truecasesWords() bool {
+	return l.options&Truecase != 0 && l.resources.Truecaser != nil
+}
+
 // run receives strings from the input channel;
 // then, scan the string, storing the emitted tokens;
 // finally, send the tokens back through the output channel;
-// break the loop and send back `nil` if the input is closed
+// break the loop and send back `nil` if the input is closed;
+// logs its startup and shutdown only verbosely (-v 1),
+// as servers start a lexer per text
 func (l *lexer) run() {
-	options := make([]string, 7)
-	if l.emitsSpaces() {
-		options[0] = "Spaces "
-	}
-	if l.emitsLinebreaks() {
-		options[1] = "Linebreaks "
-	}
-	if l.unescapesEntities() {
-		options[2] = "Entities "
-	}
-	if l.normalizesQuotes() {
-		options[3] = "Quotes "
-	}
-	if l.lowersWords() {
-		options[4] = "Lowercase "
-	}
-	if l.expandsGreek() {
-		options[5] = "Greek "
+	glog.V(1).Infof("%s starting up; options: %s\n", l.name, describeOptions(l.options))
+
+	for data, ok := l.receive(); ok; data, ok = l.receive() {
+		l.lex(data)
 	}
-	if l.mapsHyphens() {
-		options[6] = "Hyphens "
+
+	close(l.output)
+	glog.V(1).Infof("%s shutting down\n", l.name)
+}
+
+// receive returns the next input (that might have been received ahead of time),
+// or false if the input channel is closed
+func (l *lexer) receive() (string, bool) {
+	if l.peeked {
+		l.peeked = false
+		return l.next, true
 	}
-	glog.Infof("%s starting up; options: %s\n", l.name, strings.Join(options, ""))
 
-	for data := range l.input {
-		l.width = 0
-		l.pos = 0
-		l.start = 0
-		l.buffer = data
-		for state := lexText; state != nil; {
-			state = state(l)
-		}
+	data, ok := <-l.input
+	return data, ok
+}
+
+// peekInput receives the next input ahead of time (unless it was already);
+// returns false if the input channel is closed
+func (l *lexer) peekInput() bool {
+	if !l.peeked {
+		l.next, l.peeked = <-l.input
 	}
 
-	close(l.output)
-	glog.Infof("%s shutting down\n", l.name)
+	return l.peeked
 }
 
 // lastRune decodes the last rune once more from the buffer
@@ -346,19 +592,132 @@ func (l *lexer) lastRune() (r rune) {
 	return
 }
 
+// lex scans the input string, emitting its tokens
+func (l *lexer) lex(data string) {
+	l.width = 0
+	l.pos = 0
+	l.start = 0
+	l.raw = data
+	l.buffer = data
+	l.edits = l.edits[:0]
+	l.shapes.reset(0)
+	l.emphasis = ""
+	l.sentenceStart = true
+	l.headline = l.truecasesWords() && isHeadline(data)
+	state := lexText
+	failing := l.failsOnInvalid() && indexInvalid(data) != -1
+
+	if l.skip > 0 {
+		// skip the end of the word joined with the last input
+		l.pos, l.start = l.skip, l.skip
+		l.sentenceStart = false
+		l.skip = 0
+	}
+
+	if failing {
+		state = lexFailure // (a fenced code block or markup waits)
+	} else if l.segmentsWords() {
+		state = lexUAX29
+	} else if l.fence != nil {
+		state = lexCodeBlock // continue a fenced code block
+	} else if l.stripsMarkup() && l.markup.continues() {
+		state = lexMarkup // continue a tag, comment, or script
+	}
+
+	for state != nil {
+		state = state(l)
+	}
+}
+
+// replace substitutes the buffer's content between two positions,
+// recording the edit to map the buffer back onto the raw input
+func (l *lexer) replace(from, to int, with string) {
+	l.buffer = l.buffer[:from] + with + l.buffer[to:]
+	l.edits = append(l.edits, edit{from, to - from, len(with)})
+
+	if l.annotatesShapes() {
+		l.shapes.truncate(from) // (reshaped when scanned again)
+	}
+}
+
+// rawPosition maps a buffer position back onto the raw input;
+// positions inside a replacement map to the start of the replaced string
+// (or to its end, if the position is the end of a token)
+func (l *lexer) rawPosition(pos int, end bool) int {
+	for i := len(l.edits) - 1; i >= 0; i-- {
+		e := l.edits[i]
+
+		if pos >= e.at+e.new {
+			pos += e.old - e.new
+		} else if pos > e.at {
+			if end {
+				pos = e.at + e.old
+			} else {
+				pos = e.at
+			}
+		}
+	}
+	return pos
+}
+
 // emit outputs the scanned token,
 // assigning it the given class;
-// lowercase words as requested;
+// annotates shapes and lowercase words as requested;
 // moves the scanner start offset
 func (l *lexer) emit(class TokenClass) {
-	value := l.buffer[l.start:l.pos]
+	token := Token{Class: class, Value: l.buffer[l.start:l.pos]}
+
+	if len(l.edits) == 0 {
+		token.Raw = token.Value
+		token.Start, token.End = l.start, l.pos
+	} else {
+		token.Start, token.End = l.rawPosition(l.start, false), l.rawPosition(l.pos, true)
+		token.Raw = l.raw[token.Start:token.End]
+	}
+
+	if l.splitsScripts() && class != EndToken {
+		token.Script = dominantScript(token.Value)
+	}
+
+	if l.annotatesShapes() && class != EndToken {
+		l.shapeToken()
+		token.Shape, token.Features = l.shapes.result()
+		if l.greek {
+			token.Features |= GreekFeature
+		}
+	}
 
-	if l.lowersWords() && class == WordToken {
-		value = strings.ToLower(value)
+	if l.shortensElongations() && (class == WordToken || class == HashtagToken) {
+		token.Value = shortenElongations(token.Value)
 	}
 
-	l.output <- Token{Class: class, Value: value}
+	if l.truecasesWords() {
+		l.truecase(&token)
+	} else if l.lowersWords() && class == WordToken {
+		token.Value = strings.ToLower(token.Value)
+	}
+
+	l.output <- token
 	l.start = l.pos
+	l.greek = false
+	l.script = ""
+}
+
+// truecase restores the true case of sentence-initial and headline words;
+// tracks if the next token starts a sentence
+func (l *lexer) truecase(token *Token) {
+	switch token.Class {
+	case WordToken:
+		if l.sentenceStart || l.headline {
+			token.Value = l.resources.Truecaser.Truecase(token.Value)
+		}
+		l.sentenceStart = false
+	case NumberToken:
+		l.sentenceStart = false
+	case SymbolToken:
+		l.sentenceStart = l.sentenceStart ||
+			strings.Contains(sentenceTerminals, token.Value)
+	}
 }
 
 // scan returns the next rune in the buffer;
@@ -372,16 +731,42 @@ func (l *lexer) scan() (r rune) {
 
 	r, l.width = utf8.DecodeRuneInString(l.buffer[l.pos:])
 
+	if l.replacesInvalid() && invalidWidth(l.buffer[l.pos:]) != 0 {
+		l.replace(l.pos, l.pos+l.width, string(utf8.RuneError))
+		r, l.width = utf8.RuneError, utf8.RuneLen(utf8.RuneError)
+	}
+
+	if l.cleansOCR() {
+		r = l.cleanOCR(r)
+	}
+
 	if l.mapsHyphens() && strings.IndexRune(hyphens, r) != -1 {
-		l.buffer = l.buffer[:l.pos] + "-" + l.buffer[l.pos+l.width:]
+		l.replace(l.pos, l.pos+l.width, "-")
 		l.width = len("-")
 		r = '-'
 	}
 
+	if l.annotatesShapes() {
+		l.shapeToken()
+		l.shapes.add(r, l.pos+l.width)
+	}
+
 	l.pos += l.width
 	return r
 }
 
+// shapeToken aligns the shape with the current token up to the scanner's position,
+// dropping the runes undone and shaping the runes skipped over
+// (instead of scanned)
+func (l *lexer) shapeToken() {
+	if l.shapes.start != l.start {
+		l.shapes.reset(l.start)
+	}
+
+	l.shapes.truncate(l.pos)
+	l.shapes.fill(l.buffer, l.pos)
+}
+
 // ignore skips over the scanned runes (instead of emitting them);
 // moves the scanner's start offset
 func (l *lexer) ignore() {
@@ -460,9 +845,7 @@ func (l *lexer) probeEntity() bool {
 			alt := html.UnescapeString(orig)
 
 			if alt != orig {
-				before := l.buffer[:l.pos-l.width]
-				after := l.buffer[l.pos-l.width+idx[1]:]
-				l.buffer = before + alt + after
+				l.replace(l.pos-l.width, l.pos-l.width+idx[1], alt)
 				l.pos -= l.width
 				return true
 			}
@@ -482,6 +865,11 @@ func lexText(l *lexer) stateFn {
 	for {
 		// emit a stateFn by switching on the rune's category
 		switch r := l.scan(); {
+		case l.emitsInvalid() && l.width > 0 && invalidWidth(l.buffer[l.pos-l.width:]) != 0:
+			for w := invalidWidth(l.buffer[l.pos:]); w != 0; w = invalidWidth(l.buffer[l.pos:]) {
+				l.pos += w
+			}
+			l.emit(InvalidToken) // invalid UTF-8 or control characters
 		case r == 0:
 			return lexEnd // end
 		case unicode.IsLetter(r):
@@ -503,6 +891,10 @@ func lexText(l *lexer) stateFn {
 			} else {
 				l.ignore()
 			}
+		case l.readsMarkdown() && strings.ContainsRune(markdownRunes, r):
+			return lexMarkdown // Markdown syntax (or symbol)
+		case r == '<' && l.stripsMarkup():
+			return lexTag // tag (or symbol)
 		case isSymbol(r):
 			return lexSymbol // symbol
 		default:
@@ -534,6 +926,10 @@ func lexWord(l *lexer) stateFn {
 			p := l.peek()
 			if isLetterOrDigit(p) {
 				continue
+			} else if r == '-' && l.dehyphenates() && l.joinLinebreak() {
+				continue // the word goes on after the linebreak
+			} else if r == '-' && l.dehyphenates() && l.joinNextInput() {
+				continue // the word goes on in the next input
 			} else if p == '&' && l.unescapesEntities() {
 				// check if an entity is coming along
 				off := l.pos - l.width
@@ -546,6 +942,9 @@ func lexWord(l *lexer) stateFn {
 					l.pos = off
 					l.width = 0
 				}
+			} else if r == '.' && l.keepsAbbreviations() &&
+				l.isAbbreviation(l.buffer[l.start:l.pos-1]) {
+				// keep the period of the abbreviation
 			} else {
 				l.undo() // drop r from the word
 			}
@@ -557,21 +956,93 @@ func lexWord(l *lexer) stateFn {
 			}
 		case !isLetterOrDigit(r):
 			l.undo() // drop r from the word
+		case l.segmentsScripts() && segmentClass(r) != noSegment:
+			l.undo()
+			if l.pos == l.start {
+				return lexSegment // segment the run
+			} // else: end the word before the run
+		case l.splitsScripts() && l.changesScript(r):
+			l.undo()
+			if last, _ := utf8.DecodeLastRuneInString(l.buffer[l.start:l.pos]); strings.ContainsRune("-._", last) {
+				l.pos-- // drop the connector from the word, too
+			}
 		default:
 			if l.expandsGreek() && greekLetter[r] != "" {
-				before := l.buffer[:l.pos-l.width]
-				after := l.buffer[l.pos:]
-				l.buffer = before + greekLetter[r] + after
+				l.replace(l.pos-l.width, l.pos, greekLetter[r])
+				l.greek = true
 				// move ahead (everything part of the word)
 				l.pos += len(greekLetter[r]) - l.width
 			}
 			continue
 		}
-		l.emit(WordToken)
+		if l.keepsBioNames() && l.acceptBioName() {
+			continue // the name goes on
+		}
+		if l.cleansOCR() && l.joinSplitWord() {
+			continue // the word goes on after the spaces
+		}
+		if l.splitsHyphenated() && strings.Contains(l.buffer[l.start:l.pos], "-") {
+			l.emitHyphenated()
+		} else {
+			l.emit(WordToken)
+		}
 		return lexText // scan next token
 	}
 }
 
+// acceptBioName consumes runes that continue a biomedical name
+// where a regular word would end:
+// charges ("Ca2+"), primes after locants ("5'-UTR", "N,N'"),
+// and commas between locants ("N,N-dimethyl", "α1,3");
+// returns true if any runes were consumed
+func (l *lexer) acceptBioName() bool {
+	last, _ := utf8.DecodeLastRuneInString(l.buffer[l.start:l.pos])
+	pos := l.pos
+
+	switch r := l.scan(); {
+	case r == '+' && isLetterOrDigit(last):
+		l.acceptAll("+")
+		if !isLetterOrDigit(l.peek()) {
+			return true
+		}
+	case strings.ContainsRune(primes, r):
+		l.undo()
+		if l.endsWithLocant() {
+			l.scan()
+			return true
+		}
+	case r == ',':
+		p := l.peek()
+		l.undo()
+		if l.endsWithLocant() &&
+			(unicode.IsDigit(last) && unicode.IsDigit(p) ||
+				unicode.IsUpper(last) && unicode.IsUpper(p)) {
+			l.scan()
+			return true
+		}
+	}
+
+	l.pos = pos
+	l.width = 0
+	return false
+}
+
+// endsWithLocant is true if the current token ends with
+// a digit or a single uppercase letter
+func (l *lexer) endsWithLocant() bool {
+	token := l.buffer[l.start:l.pos]
+	last, w := utf8.DecodeLastRuneInString(token)
+
+	if unicode.IsDigit(last) {
+		return true
+	} else if unicode.IsUpper(last) {
+		prev, _ := utf8.DecodeLastRuneInString(token[:len(token)-w])
+		return !unicode.IsLetter(prev)
+	}
+
+	return false
+}
+
 // lexNumber consumes and produces a number
 func lexNumber(l *lexer) stateFn {
 	l.acceptOn(unicode.IsDigit)
@@ -594,6 +1065,11 @@ func lexNumber(l *lexer) stateFn {
 	case unicode.IsLetter(r):
 		l.undo()
 		return lexWord // treat as word
+	case l.keepsBioNames() && strings.ContainsRune(primes, r):
+		l.undo()
+		return lexWord // nucleotide position ("5'-UTR")
+	case l.keepsBioNames() && r == '-' && unicode.IsLetter(l.peek()):
+		return lexWord // locants ("5-HT", "1,2-dichloroethane")
 	default:
 		l.undo()
 	}
@@ -612,19 +1088,37 @@ func lexNumber(l *lexer) stateFn {
 // HTML entity name, lex that entity instead.
 // If there are two singe quotes, normalize
 // it.
+// If biomedical names are kept and the symbol
+// opens a stereochemical prefix, lex a word instead.
+// If social media tokens are lexed and the symbol
+// starts one, emit that token instead.
 func lexSymbol(l *lexer) stateFn {
 	r := l.lastRune()
 
+	if l.readsSocial() {
+		if class := l.acceptSocial(); class != SymbolToken {
+			l.emit(class)
+			return lexText
+		}
+	}
+
+	if r == '(' && l.keepsBioNames() {
+		if idx := stereoPrefix.FindStringIndex(l.buffer[l.pos-l.width:]); idx != nil {
+			// consume the prefix up to its hyphen
+			_, w := utf8.DecodeLastRuneInString(l.buffer[:l.pos-l.width+idx[1]])
+			l.pos += idx[1] - l.width - w
+			l.width = 0
+			return lexWord
+		}
+	}
+
 	if r == '&' && l.probeEntity() {
 		return lexText // retry scan...
 	} else if l.normalizesQuotes() && normalQuote[r] != "" && l.peek() == r {
-		before := l.buffer[:l.pos-l.width]
-		after := l.buffer[l.pos+l.width:]
-		l.buffer = before + normalQuote[r] + after
+		l.replace(l.pos-l.width, l.pos+l.width, normalQuote[r])
 	} else if l.normalizesQuotes() && r == '\u02bc' {
-		before := l.buffer[:l.pos-l.width]
-		after := l.buffer[l.pos:]
-		l.buffer = before + "'" + after
+		l.replace(l.pos-l.width, l.pos, "'")
+		l.pos -= l.width - len("'")
 	}
 
 	l.emit(SymbolToken)
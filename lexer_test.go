@@ -1,7 +1,11 @@
 package tokenizer
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -79,7 +83,7 @@ func simpleLexerTest(t *testing.T, description string, check checkFn, testCases
 					cnt++
 
 					if !check(token) {
-						t.Errorf("%s: check failed for %s", token.String())
+						t.Errorf("%s: check failed for %s", description, token.String())
 					}
 				}
 			}
@@ -98,7 +102,7 @@ func ensureClosing(t *testing.T, description string, in chan string, out chan To
 	tok, ok := <-out
 
 	if ok {
-		t.Errorf("%s: the output channel did not close, got %s", description, tok)
+		t.Errorf("%s: the output channel did not close, got %s", description, tok.String())
 	}
 }
 
@@ -284,3 +288,829 @@ func TestFullLexing(t *testing.T) {
 		fullLexerTest(t, test.description, test.line, test.expected)
 	}
 }
+
+type lexerModeTestCase struct {
+	description string
+	line        string
+	expected    []string
+}
+
+func modeLexerTest(t *testing.T, options Option, description, line string, expected []string) {
+	linesLexerTest(t, options, nil, description, []string{line}, append(expected[:len(expected):len(expected)], "|"))
+}
+
+// linesLexerTest lexes the lines with the options and resources,
+// and compares the token values to the expected ones,
+// with a "|" for the EndToken of each line
+func linesLexerTest(t *testing.T, options Option, resources *Resources, description string, lines []string, expected []string) {
+	in := make(chan string, len(lines))
+
+	for _, line := range lines {
+		in <- line
+	}
+
+	close(in)
+	var values []string
+
+	for token := range LexWith(in, 100, options, resources) {
+		if token.IsEnd() {
+			values = append(values, "|")
+		} else {
+			values = append(values, token.Value)
+		}
+	}
+
+	if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", expected) {
+		t.Errorf("%s: expected %q, got %q", description, expected, values)
+	}
+}
+
+var lexerBioCases = []lexerModeTestCase{
+	{"interleukin", "IL-2 levels", []string{"IL-2", "levels"}},
+	{"protein", "p53.", []string{"p53", "."}},
+	{"Greek letter", "TNF-α", []string{"TNF-α"}},
+	{"charge", "Ca2+ ions", []string{"Ca2+", "ions"}},
+	{"charges", "Fe3++", []string{"Fe3++"}},
+	{"charged name", "Ca2+-dependent", []string{"Ca2+-dependent"}},
+	{"no charge between words", "x+y", []string{"x", "+", "y"}},
+	{"nucleotide position", "the 5'-UTR", []string{"the", "5'-UTR"}},
+	{"nucleotide position without hyphen", "3'UTR", []string{"3'UTR"}},
+	{"nucleotide position alone", "5' end", []string{"5'", "end"}},
+	{"locant prime", "N,N'-dimethylurea", []string{"N,N'-dimethylurea"}},
+	{"letter locants", "N,N-dimethyl", []string{"N,N-dimethyl"}},
+	{"digit locants", "1,2-dichloroethane", []string{"1,2-dichloroethane"}},
+	{"many locants", "2,3,7,8-TCDD", []string{"2,3,7,8-TCDD"}},
+	{"locants in word", "α1,3-fucosyltransferase", []string{"α1,3-fucosyltransferase"}},
+	{"no locants", "red,green", []string{"red", ",", "green"}},
+	{"digit prefix", "5-HT", []string{"5-HT"}},
+	{"number ranges", "1-2", []string{"1", "-", "2"}},
+	{"stereo prefix", "(R)-2-butanol", []string{"(R)-2-butanol"}},
+	{"optical rotation", "(+)-catechin", []string{"(+)-catechin"}},
+	{"multiple stereo centers", "(2S,3R)-threonine", []string{"(2S,3R)-threonine"}},
+	{"no stereo prefix", "(R) 2", []string{"(", "R", ")", "2"}},
+	{"possessive", "Anselm's", []string{"Anselm", "'", "s"}},
+}
+
+func TestBioMode(t *testing.T) {
+	for _, test := range lexerBioCases {
+		modeLexerTest(t, Bio, test.description, test.line, test.expected)
+	}
+}
+
+var bioCorpus = []string{
+	"IL-2 and TNF-α induce p53 in Ca2+-dependent manner.",
+	"The 5'-UTR of the gene binds N,N-dimethylformamide.",
+	"(R)-2-butanol and 2,3,7,8-TCDD were measured at 5-HT receptors.",
+	"Gene 23p, too; Fe3+ and Na+ ions in the 3' end.",
+}
+
+func BenchmarkBioMode(b *testing.B) {
+	in := make(chan string)
+	out := Lex(in, 100, Bio|Greek)
+
+	for i := 0; i < b.N; i++ {
+		in <- bioCorpus[i%len(bioCorpus)]
+		for token := range out {
+			if token.IsEnd() {
+				break
+			}
+		}
+	}
+
+	close(in)
+}
+
+type shapeTestCase struct {
+	value    string
+	shape    string
+	features Feature
+}
+
+var shapeCases = []shapeTestCase{
+	{"Appleseed", "Xxxxx", TitleCaseFeature},
+	{"apple", "xxxx", LowerCaseFeature},
+	{"NASA", "XXXX", AllCapsFeature},
+	{"iPhone", "xXxxxx", MixedCaseFeature},
+	{"McDonald", "XxXxxxx", MixedCaseFeature},
+	{"12-34", "dd-dd", DigitFeature | HyphenFeature},
+	{"IL22", "XXdd", AllCapsFeature | DigitFeature},
+	{"TNF-α", "XXX-x", MixedCaseFeature | HyphenFeature | GreekFeature},
+	{".", ".", 0},
+}
+
+func TestShape(t *testing.T) {
+	for _, test := range shapeCases {
+		shape, features := Shape(test.value)
+
+		if shape != test.shape {
+			t.Errorf("%q: expected shape %q, got %q", test.value, test.shape, shape)
+		}
+		if features != test.features {
+			t.Errorf("%q: expected features %s, got %s", test.value, test.features, features)
+		}
+	}
+}
+
+func TestShapesOption(t *testing.T) {
+	in := make(chan string, 1)
+	in <- "IL-β Up"
+	close(in)
+	expected := []Token{
+		{Class: WordToken, Value: "il-beta", Raw: "IL-β", Start: 0, End: 5,
+			Shape: "XX-xxxx", Features: MixedCaseFeature | HyphenFeature | GreekFeature},
+		{Class: WordToken, Value: "up", Raw: "Up", Start: 6, End: 8, Shape: "Xx", Features: TitleCaseFeature},
+		{Class: EndToken, Start: 8, End: 8},
+	}
+	i := 0
+
+	for token := range Lex(in, 10, Shapes|Greek|Lowercase) {
+		if i >= len(expected) {
+			t.Errorf("unexpected token %s", token.String())
+		} else if token != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], token)
+		}
+		i++
+	}
+}
+
+// the shapes computed while lexing must be the shapes of the token values
+func TestShapesWhileLexing(t *testing.T) {
+	lines := []string{
+		"IL-2 and TNF-&alpha; (R)-2-butanol in 5'-UTR, Ca2+ and N,N-dimethyl",
+		"The ﬁrst state-of-the-art infor-\nmation – \"quoted\" text…",
+		"#NLProc @user $AAPL :-) <3 **bold** `code` [link](http://x.org)",
+		"Mixed αβγ-Chains ΑΒΓ 123.456,78 AAAAAAAh x.y.z. e.g. Dr. Smith",
+		"日本語のテキスト and ไทย mixed with Latin",
+		"<p>Some <b>markup</b> text</p>",
+	}
+
+	for _, options := range []Option{
+		Bio | Entities | Hyphens | Quotes,
+		Greek | Entities | Scripts | Spaces | Linebreaks,
+		Social | Markdown | Abbrevs,
+		OCR | Hyphens | Segment,
+		Markup | Greek,
+		UAX29,
+	} {
+		in := make(chan string, len(lines))
+
+		for _, line := range lines {
+			in <- line
+		}
+
+		close(in)
+
+		for token := range LexWith(in, 100, options|Shapes|SplitHyphenated, nil) {
+			if token.IsEnd() {
+				continue
+			}
+
+			shape, features := Shape(token.Value)
+
+			if options&Greek != 0 {
+				features |= token.Features & GreekFeature // (of expanded letters)
+			}
+
+			if token.Shape != shape || token.Features != features {
+				t.Errorf("%d: %q: expected %q %s, got %q %s", options, token.Value,
+					shape, features, token.Shape, token.Features)
+			}
+		}
+	}
+}
+
+type rawTestCase struct {
+	description string
+	options     Option
+	line        string
+	expected    []string // pairs of raw and normalized values
+}
+
+var rawCases = []rawTestCase{
+	{"no options", NoOptions, "A b", []string{"A", "A", "b", "b"}},
+	{"lowercase", Lowercase, "A b", []string{"A", "a", "b", "b"}},
+	{"entities", Entities, "x&alpha;x k&amp;k", []string{
+		"x&alpha;x", "xαx", "k", "k", "&amp;", "&", "k", "k"}},
+	{"greek", Greek | Entities, "x&alpha;x TNF-β", []string{
+		"x&alpha;x", "xalphax", "TNF-β", "TNF-beta"}},
+	{"quotes", Quotes, "''hi‘‘", []string{"''", "\"", "hi", "hi", "‘‘", "“"}},
+	{"hyphens", Hyphens | Entities, "A\u2014&beta;\u2013", []string{
+		"A\u2014&beta;", "A-β", "\u2013", "-"}},
+}
+
+func TestRawValues(t *testing.T) {
+	for _, test := range rawCases {
+		in := make(chan string, 1)
+		in <- test.line
+		close(in)
+		var values []string
+
+		for token := range Lex(in, 100, test.options) {
+			if !token.IsEnd() {
+				values = append(values, token.Raw, token.Value)
+			}
+		}
+
+		if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", test.expected) {
+			t.Errorf("%s: expected %q, got %q", test.description, test.expected, values)
+		}
+	}
+}
+
+var lexerMarkupCases = []lexerModeTestCase{
+	{"inline tags", `<b class="x">bold</b> text`, []string{"bold", "text"}},
+	{"attribute with a bracket", `<a title="a>b" href='/x'>link</a>`, []string{"link"}},
+	{"comments", "a<!-- b <p> c -->d", []string{"a", "d"}},
+	{"declarations", "<!DOCTYPE html><?xml version=\"1.0\"?>x", []string{"x"}},
+	{"script contents", "a<script>if (a < b) {}</script>b", []string{"a", "b"}},
+	{"style contents", "<STYLE>p { x: 1 }</Style>c", []string{"c"}},
+	{"unclosed script", "a<script>var x", []string{"a"}},
+	{"no tags", "a < b <3 c>", []string{"a", "<", "b", "<", "3", "c", ">"}},
+	{"block tags without linebreaks", "a<p>b<br/>c", []string{"a", "b", "c"}},
+	{"numeric entities", "&#x3B1;&#946; &#X3b3;", []string{"αβ", "γ"}},
+}
+
+func TestMarkupMode(t *testing.T) {
+	for _, test := range lexerMarkupCases {
+		modeLexerTest(t, Markup|Entities, test.description, test.line, test.expected)
+	}
+}
+
+var lexerTagsCases = []lexerModeTestCase{
+	{"tags", `<p class=x>a</p>`, []string{`<p class=x>`, "a", "</p>"}},
+	{"comments", "<!-- x -->", []string{"<!-- x -->"}},
+	{"script contents", "<script>x</script>", []string{"<script>", "</script>"}},
+}
+
+func TestTagsMode(t *testing.T) {
+	for _, test := range lexerTagsCases {
+		modeLexerTest(t, Tags, test.description, test.line, test.expected)
+	}
+}
+
+func TestMarkupLinebreaks(t *testing.T) {
+	in := make(chan string, 1)
+	in <- "a<br>b<i>c</i>"
+	close(in)
+	expected := []Token{
+		{Class: WordToken, Value: "a", Raw: "a", Start: 0, End: 1},
+		{Class: LinebreakToken, Value: "\n", Raw: "<br>", Start: 1, End: 5},
+		{Class: WordToken, Value: "b", Raw: "b", Start: 5, End: 6},
+		{Class: WordToken, Value: "c", Raw: "c", Start: 9, End: 10},
+		{Class: EndToken, Start: 14, End: 14},
+	}
+	i := 0
+
+	for token := range Lex(in, 10, Markup|Linebreaks) {
+		if i >= len(expected) {
+			t.Errorf("unexpected token %s", token.String())
+		} else if token != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], token)
+		}
+		i++
+	}
+}
+
+// lexLines lexes each line as an input, collecting the class names and values
+// of its tokens
+func lexLines(options Option, lines ...string) [][]string {
+	in := make(chan string)
+	out := Lex(in, 10, options)
+	var results [][]string
+
+	for _, line := range lines {
+		in <- line
+		values := []string{}
+
+		for token := range out {
+			if token.IsEnd() {
+				break
+			}
+
+			values = append(values, token.ClassName()+":"+token.Value)
+		}
+
+		results = append(results, values)
+	}
+
+	close(in)
+	return results
+}
+
+func TestMarkupLines(t *testing.T) {
+	lines := []string{
+		`a <div class="x"`, `title='one`, `two>' id=y>b</div> <!-- c`, "d", "-->e <script",
+		"type=x>if (a < b) {", "}</script>f <br", "/>g",
+	}
+	expected := [][]string{
+		{"Word:a"}, {}, {"Word:b"}, {}, {"Word:e"}, {}, {"Word:f"}, {"Word:g"},
+	}
+
+	if results := lexLines(Markup, lines...); fmt.Sprintf("%q", results) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, results)
+	}
+
+	expected = [][]string{
+		{"Word:a", `Tag:<div class="x"`}, {"Tag:title='one"}, {"Tag:two>' id=y>", "Word:b", "Tag:</div>", "Tag:<!-- c"},
+		{"Tag:d"}, {"Tag:-->", "Word:e", "Tag:<script"}, {"Tag:type=x>"}, {"Tag:</script>", "Word:f", "Tag:<br"}, {"Tag:/>", "Word:g"},
+	}
+
+	if results := lexLines(Tags, lines...); fmt.Sprintf("%q", results) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, results)
+	}
+
+	expected = [][]string{{"Word:a"}, {"Linebreak:\n"}, {"Word:b"}}
+
+	if results := lexLines(Markup|Linebreaks, "a", "<p", ">b"); fmt.Sprintf("%q", results) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, results)
+	}
+}
+
+var lexerMarkdownCases = []lexerModeTestCase{
+	{"headings", "## A #1 title", []string{"A", "#", "1", "title"}},
+	{"no heading", "#hash", []string{"#", "hash"}},
+	{"emphasis", "**bold** and _it_ or ~~no~~", []string{"bold", "and", "it", "or", "no"}},
+	{"no emphasis", "2 * 3 = 6, a ** b, x*y or ~~ z", []string{
+		"2", "*", "3", "=", "6", ",", "a", "*", "*", "b", ",", "x", "*", "y", "or", "~", "~", "z"}},
+	{"unclosed emphasis", "*a and b_", []string{"*", "a", "and", "b", "_"}},
+	{"nested emphasis", "***both*** and *a **b** c*", []string{"both", "and", "a", "b", "c"}},
+	{"intraword emphasis", "un*frigging*believable", []string{"un", "frigging", "believable"}},
+	{"snake case", "a snake_case word", []string{"a", "snake_case", "word"}},
+	{"bullets", "* one\n  - two\n+ three", []string{"one", "two", "three"}},
+	{"not a bullet", "a - b", []string{"a", "-", "b"}},
+	{"rules", "a\n---\n***\nb", []string{"a", "b"}},
+	{"block quotes", "> quoted > text", []string{"quoted", ">", "text"}},
+	{"code spans", "use `go test ./...` or `` a`b ``", []string{"use", "go test ./...", "or", "a`b"}},
+	{"unclosed code span", "a `b", []string{"a", "`", "b"}},
+	{"links", "see [the *docs*](http://x.org/a_b \"Title\").", []string{
+		"see", "the", "docs", "http://x.org/a_b", "."}},
+	{"images", "![alt text](img.png)", []string{"alt", "text", "img.png"}},
+	{"no link", "[1] and [a](b", []string{"[", "1", "]", "and", "[", "a", "]", "(", "b"}},
+	{"autolinks", "<https://fnl.es> <b>", []string{"https://fnl.es", "<", "b", ">"}},
+	{"references", "[id]: http://x.org \"T\"", []string{"http://x.org", "\"", "T", "\""}},
+	{"fenced code", "a\n```go\nx := 1\ny()\n```\nb", []string{"a", "x := 1\ny()", "b"}},
+}
+
+func TestMarkdownMode(t *testing.T) {
+	for _, test := range lexerMarkdownCases {
+		modeLexerTest(t, Markdown, test.description, test.line, test.expected)
+	}
+}
+
+func TestMarkdownCodeBlockLines(t *testing.T) {
+	in := make(chan string)
+	out := Lex(in, 10, Markdown)
+	lines := []string{"a", "~~~~", "x  y", "", "~~~", "z", "~~~~", "b"}
+	var values []string
+
+	for _, line := range lines {
+		in <- line
+
+		for token := range out {
+			if token.IsEnd() {
+				break
+			}
+
+			values = append(values, token.ClassName()+":"+token.Value)
+		}
+	}
+
+	close(in)
+	expected := []string{"Word:a", "Code:x  y", "Code:~~~", "Code:z", "Word:b"}
+
+	if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, values)
+	}
+}
+
+var lexerSocialCases = []lexerModeTestCase{
+	{"hashtags", "#NLProc rocks #2024 #c_3po", []string{"#NLProc", "rocks", "#", "2024", "#c_3po"}},
+	{"no hashtag", "C# and F#.", []string{"C", "#", "and", "F", "#", "."}},
+	{"mentions", "@fnl: hi @_x", []string{"@fnl", ":", "hi", "@_x"}},
+	{"no mention", "a@b.com", []string{"a", "@", "b.com"}},
+	{"cashtags", "$AAPL up, $BRK.B too", []string{"$AAPL", "up", ",", "$BRK.B", "too"}},
+	{"no cashtag", "$100 or $ABCDEFGH", []string{"$", "100", "or", "$", "ABCDEFGH"}},
+	{"emoticons", "hi :-) :P ;) <3 </3 ^_^ -_- :'(", []string{
+		"hi", ":-)", ":P", ";)", "<3", "</3", "^_^", "-_-", ":'("}},
+	{"emoticons before punctuation", "great :)!", []string{"great", ":)", "!"}},
+	{"reversed emoticons", "(: (-;", []string{"(:", "(-;"}},
+	{"no emoticons", "http://x.org 10:30 a:b", []string{
+		"http", ":", "/", "/", "x.org", "10", ":", "30", "a", ":", "b"}},
+	{"no glued emoticons", "great:) x=3. (see above): text", []string{
+		"great", ":", ")", "x", "=", "3", ".", "(", "see", "above", ")", ":", "text"}},
+}
+
+func TestSocialMode(t *testing.T) {
+	for _, test := range lexerSocialCases {
+		modeLexerTest(t, Social, test.description, test.line, test.expected)
+	}
+}
+
+var lexerElongationCases = []lexerModeTestCase{
+	{"elongations", "sooooo goooood #yessss", []string{"sooo", "goood", "#yesss"}},
+	{"three letters", "sooo www", []string{"sooo", "www"}},
+	{"several", "aaaaabbbbbcccc1111", []string{"aaabbbccc1111"}},
+}
+
+func TestElongations(t *testing.T) {
+	for _, test := range lexerElongationCases {
+		modeLexerTest(t, Social|Elongations, test.description, test.line, test.expected)
+	}
+}
+
+var lexerSegmentCases = []lexerModeTestCase{
+	{"Han ideographs", "我爱北京。", []string{"我", "爱", "北", "京", "。"}},
+	{"mixed scripts", "我爱Go语言123", []string{"我", "爱", "Go", "语", "言", "123"}},
+	{"kana", "コーヒーをください", []string{"コーヒー", "をください"}},
+	{"Thai", "ภาษาไทย ok", []string{"ภาษาไทย", "ok"}},
+	{"Latin", "六 abc", []string{"六", "abc"}},
+}
+
+func TestSegmentMode(t *testing.T) {
+	for _, test := range lexerSegmentCases {
+		modeLexerTest(t, Segment, test.description, test.line, test.expected)
+	}
+}
+
+var lexerDictionarySegmentCases = []lexerModeTestCase{
+	{"Chinese", "我爱北京天安门。", []string{"我", "爱", "北京", "天安门", "。"}},
+	{"longest match", "北京大学生", []string{"北京大学", "生"}},
+	{"Japanese", "東京でコーヒーを飲む", []string{"東京", "で", "コーヒー", "を", "飲", "む"}},
+	{"Thai", "ภาษาไทยง่ายมาก", []string{"ภาษา", "ไทย", "ง่าย", "มาก"}},
+	{"unknown Thai", "ภาษากขคไทย", []string{"ภาษา", "กขค", "ไทย"}},
+}
+
+func TestSegmentDictionaries(t *testing.T) {
+	resources := &Resources{
+		CJK:  NewDictionary([]string{"北京", "天安门", "北京大学", "大学", "東京", "コーヒー", "で", "を"}),
+		Thai: NewDictionary([]string{"ภาษา", "ไทย", "ง่าย", "มาก"}),
+	}
+
+	for _, test := range lexerDictionarySegmentCases {
+		in := make(chan string, 1)
+		in <- test.line
+		close(in)
+		var values []string
+
+		for token := range LexWith(in, 100, Segment, resources) {
+			if !token.IsEnd() {
+				values = append(values, token.Value)
+			}
+		}
+
+		if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", test.expected) {
+			t.Errorf("%s: expected %q, got %q", test.description, test.expected, values)
+		}
+	}
+}
+
+func TestReadDictionary(t *testing.T) {
+	d, err := ReadDictionary(strings.NewReader("北京 34488 ns\n\n天安门\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !d.Contains("北京") || !d.Contains("天安门") || d.Contains("34488") || d.maxLen != 3 {
+		t.Errorf("unexpected dictionary %v", d)
+	}
+}
+
+var lexerScriptsCases = []lexerModeTestCase{
+	{"Latin and Cyrillic", "Zürichstraßeиван", []string{"Zürichstraße", "иван"}},
+	{"connected scripts", "Zürich-иван", []string{"Zürich", "-", "иван"}},
+	{"Latin and Greek", "TNF-α", []string{"TNF", "-", "α"}},
+	{"digits", "abc123иван", []string{"abc123", "иван"}},
+	{"same script", "Ελληνικά ok", []string{"Ελληνικά", "ok"}},
+}
+
+func TestScriptsMode(t *testing.T) {
+	for _, test := range lexerScriptsCases {
+		modeLexerTest(t, Scripts, test.description, test.line, test.expected)
+	}
+}
+
+func TestScriptsAnnotation(t *testing.T) {
+	in := make(chan string, 1)
+	in <- "TNF-α иван 123 Σα"
+	close(in)
+	expected := []string{"TNF-alpha:Latin", "иван:Cyrillic", "123:Common", "Sigmaalpha:Latin", ":"}
+	var values []string
+
+	for token := range Lex(in, 10, Scripts|Greek) {
+		values = append(values, token.Value+":"+token.Script)
+	}
+
+	if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, values)
+	}
+}
+
+func TestDominantScript(t *testing.T) {
+	for value, script := range map[string]string{
+		"abc": "Latin", "αβγ": "Greek", "aβγ": "Greek", "aβ": "Latin",
+		"12.": "Common", "ابجد": "Arabic", "ᚠᚢ": "Runic",
+	} {
+		if dominantScript(value) != script {
+			t.Errorf("%q: expected %s, got %s", value, script, dominantScript(value))
+		}
+	}
+}
+
+var lexerUAX29Cases = []lexerModeTestCase{
+	{"words", "The quick (\"brown\") fox can't jump 32.3 feet, right?", []string{
+		"The", "quick", "(", "\"", "brown", "\"", ")", "fox", "can't",
+		"jump", "32.3", "feet", ",", "right", "?"}},
+	{"connectors", "e.g. foo_bar a-b 3,000.5", []string{
+		"e.g", ".", "foo_bar", "a", "-", "b", "3,000.5"}},
+	{"Katakana and Han", "カタカナ漢字", []string{"カタカナ", "漢", "字"}},
+	{"flags", "🇩🇪🇫🇷", []string{"🇩🇪", "🇫🇷"}},
+	{"linebreaks", "a\r\nb", []string{"a", "b"}},
+}
+
+func TestUAX29Mode(t *testing.T) {
+	for _, test := range lexerUAX29Cases {
+		modeLexerTest(t, UAX29, test.description, test.line, test.expected)
+	}
+}
+
+func TestUAX29Spaces(t *testing.T) {
+	modeLexerTest(t, UAX29|Spaces|Linebreaks|Lowercase, "spaces", "Ab  c\n", []string{"ab", "  ", "c", "\n"})
+}
+
+// the official Unicode word break test cases
+func TestWordBreakTest(t *testing.T) {
+	file, err := os.Open("testdata/WordBreakTest.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	cases := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		if i := strings.IndexByte(text, '#'); i != -1 {
+			text = text[:i]
+		}
+
+		var input strings.Builder
+		var expected []string
+		start := 0
+
+		for _, field := range strings.Fields(text) {
+			switch field {
+			case "÷":
+				if input.Len() > start {
+					expected = append(expected, input.String()[start:])
+					start = input.Len()
+				}
+			case "×":
+			default:
+				cp, err := strconv.ParseUint(field, 16, 32)
+
+				if err != nil {
+					t.Fatalf("line %d: %s", line, err)
+				}
+
+				input.WriteRune(rune(cp))
+			}
+		}
+
+		if len(expected) == 0 {
+			continue
+		}
+
+		var segments []string
+
+		for rest := input.String(); rest != ""; {
+			n := wordBoundary(rest)
+			segments = append(segments, rest[:n])
+			rest = rest[n:]
+		}
+
+		if fmt.Sprintf("%q", segments) != fmt.Sprintf("%q", expected) {
+			t.Errorf("line %d: expected %q, got %q", line, expected, segments)
+		}
+
+		cases++
+	}
+
+	if err = scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cases == 0 {
+		t.Error("no test cases found")
+	}
+}
+
+var lexerAbbrevsCases = []lexerModeTestCase{
+	{"titles", "Dr. Smith and Prof. Jones", []string{"Dr.", "Smith", "and", "Prof.", "Jones"}},
+	{"initials", "J. R. R. Tolkien", []string{"J.", "R.", "R.", "Tolkien"}},
+	{"acronyms", "the U.S. and e.g. this", []string{"the", "U.S.", "and", "e.g.", "this"}},
+	{"sentence end", "apples, pears, etc.", []string{"apples", ",", "pears", ",", "etc."}},
+	{"no abbreviation", "He left. I stayed a.", []string{"He", "left", ".", "I", "stayed", "a", "."}},
+}
+
+func TestAbbrevsMode(t *testing.T) {
+	for _, test := range lexerAbbrevsCases {
+		modeLexerTest(t, Abbrevs, test.description, test.line, test.expected)
+	}
+}
+
+func TestAbbrevsResources(t *testing.T) {
+	resources := &Resources{Abbreviations: NewAbbreviations(append(BuiltinAbbreviations("de"), "Fr."))}
+
+	linesLexerTest(t, Abbrevs, resources, "German", []string{"Dr. Meier bzw. Fr. Müller"},
+		[]string{"Dr.", "Meier", "bzw.", "Fr.", "Müller", "|"})
+}
+
+func TestReadAbbreviations(t *testing.T) {
+	abbreviations, err := ReadAbbreviations(strings.NewReader("Dr.\nbzw 123\n\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for word, expected := range map[string]bool{"dr": true, "DR.": true, "bzw.": true, "123": false, "x": false} {
+		if abbreviations.Contains(word) != expected {
+			t.Errorf("%q: expected %v", word, expected)
+		}
+	}
+}
+
+func TestHyphenPolicies(t *testing.T) {
+	lexicon := NewDictionary([]string{"state", "of", "the", "art"})
+	line := []string{"state-of-the-art pre-processing"}
+
+	linesLexerTest(t, NoOptions, &Resources{}, "keep", line,
+		[]string{"state-of-the-art", "pre-processing", "|"})
+	linesLexerTest(t, SplitHyphenated, nil, "split", line,
+		[]string{"state", "-", "of", "-", "the", "-", "art", "pre", "-", "processing", "|"})
+	linesLexerTest(t, AttachHyphenated, nil, "attach", line,
+		[]string{"state-", "of-", "the-", "art", "pre-", "processing", "|"})
+	linesLexerTest(t, LexiconHyphenated, &Resources{Lexicon: lexicon}, "lexicon", line,
+		[]string{"state", "-", "of", "-", "the", "-", "art", "pre-processing", "|"})
+	linesLexerTest(t, LexiconHyphenated|AttachHyphenated, &Resources{Lexicon: lexicon}, "lexicon attach", line,
+		[]string{"state-", "of-", "the-", "art", "pre-processing", "|"})
+	linesLexerTest(t, LexiconHyphenated, nil, "no lexicon", line,
+		[]string{"state-of-the-art", "pre-processing", "|"})
+}
+
+func TestDehyphenate(t *testing.T) {
+	lexicon := NewDictionary([]string{"information"})
+
+	linesLexerTest(t, Dehyphenate, nil, "linebreak", []string{"infor-\nmation and Jean-\n  Paul"},
+		[]string{"information", "and", "Jean-Paul", "|"})
+	linesLexerTest(t, Dehyphenate, nil, "across inputs", []string{"the infor-  ", "  mation is", "x"},
+		[]string{"the", "information", "|", "is", "|", "x", "|"})
+	linesLexerTest(t, Dehyphenate, nil, "no continuation", []string{"the infor-", "", "12"},
+		[]string{"the", "infor", "-", "|", "|", "12", "|"})
+	linesLexerTest(t, Dehyphenate, nil, "number continuation", []string{"the infor-", "12"},
+		[]string{"the", "infor", "-", "|", "12", "|"})
+	linesLexerTest(t, Dehyphenate, nil, "closed input", []string{"the infor-"},
+		[]string{"the", "infor", "-", "|"})
+	linesLexerTest(t, Dehyphenate, &Resources{Lexicon: lexicon}, "lexicon", []string{"infor-\nmation self-\naware"},
+		[]string{"information", "self-aware", "|"})
+	linesLexerTest(t, NoOptions, nil, "off", []string{"infor-\nmation"},
+		[]string{"infor", "-", "mation", "|"})
+}
+
+func TestDehyphenateRaw(t *testing.T) {
+	in := make(chan string, 2)
+	in <- "infor-"
+	in <- "  mation is"
+	close(in)
+	var tokens []string
+
+	for token := range Lex(in, 10, Dehyphenate) {
+		tokens = append(tokens, fmt.Sprintf("%s:%q:%d-%d", token.ClassName(), token.Raw, token.Start, token.End))
+	}
+
+	expected := []string{`Word:"infor-":0-6`, `End:"":6-6`, `Word:"is":9-11`, `End:"":11-11`}
+
+	if fmt.Sprintf("%q", tokens) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, tokens)
+	}
+}
+
+func TestHyphenatedNumbers(t *testing.T) {
+	in := make(chan string, 1)
+	in <- "COVID-19 3-5"
+	close(in)
+	var tokens []string
+
+	for token := range Lex(in, 10, SplitHyphenated) {
+		tokens = append(tokens, fmt.Sprintf("%s:%s", token.ClassName(), token.Value))
+	}
+
+	expected := []string{"Word:COVID", "Symbol:-", "Number:19", "Number:3", "Symbol:-", "Number:5", "End:"}
+
+	if fmt.Sprintf("%q", tokens) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, tokens)
+	}
+}
+
+func TestOCRMode(t *testing.T) {
+	lexicon := NewDictionary([]string{"the", "information", "age", "in", "formation"})
+	resources := &Resources{Lexicon: lexicon}
+
+	linesLexerTest(t, OCR, nil, "ligatures", []string{"ﬁnd the ﬂow"},
+		[]string{"find", "the", "flow", "|"})
+	linesLexerTest(t, OCR|Hyphens, nil, "soft hyphens", []string{"infor\u00ADmation in\u00AD\nformation"},
+		[]string{"information", "information", "|"})
+	linesLexerTest(t, OCR, nil, "soft hyphen at the end", []string{"the infor\u00AD", "mation"},
+		[]string{"the", "information", "|", "|"})
+	linesLexerTest(t, OCR, nil, "quotes", []string{",,Hallo`` and ``hi''"},
+		[]string{"„", "Hallo", "“", "and", "“", "hi", "”", "|"})
+	linesLexerTest(t, OCR, resources, "split words", []string{"the infor mation age in formation"},
+		[]string{"the", "information", "age", "in", "formation", "|"})
+	linesLexerTest(t, OCR, nil, "no lexicon", []string{"infor mation"},
+		[]string{"infor", "mation", "|"})
+	linesLexerTest(t, Hyphens, nil, "off", []string{"ﬁnd in\u00ADformation"},
+		[]string{"ﬁnd", "in-formation", "|"})
+}
+
+func TestOCRRaw(t *testing.T) {
+	in := make(chan string, 1)
+	in <- "ﬁne"
+	close(in)
+
+	for token := range Lex(in, 10, OCR) {
+		if token.IsWord() && (token.Value != "fine" || token.Raw != "ﬁne") {
+			t.Errorf("expected fine/ﬁne, got %s/%s", token.Value, token.Raw)
+		}
+	}
+}
+
+func TestInvalidPolicies(t *testing.T) {
+	line := "ab\x00\x01 c\xffd"
+
+	modeLexerTest(t, NoOptions, "ignore", "a\x01b\xff", []string{"a", "b", "\xff"})
+	modeLexerTest(t, EmitInvalid, "emit", line, []string{"ab", "\x00\x01", "c", "\xff", "d"})
+	modeLexerTest(t, ReplaceInvalid, "replace", line, []string{"ab", "�", "�", "c", "�", "d"})
+	modeLexerTest(t, FailInvalid, "fail", line, []string{"control character U+0000 at offset 2"})
+	modeLexerTest(t, FailInvalid, "fail on bytes", "ok\xfe", []string{"invalid UTF-8 byte 0xfe at offset 2"})
+	modeLexerTest(t, FailInvalid, "valid", "a\tb �", []string{"a", "b", "�"})
+	modeLexerTest(t, EmitInvalid|FailInvalid, "fail wins", "ok\xfe", []string{"invalid UTF-8 byte 0xfe at offset 2"})
+}
+
+func TestCountInvalid(t *testing.T) {
+	if bytes, controls := CountInvalid("a\tb\x00\x01\xff\xfe\n\r\u0085 �"); bytes != 2 || controls != 2 {
+		t.Errorf("expected 2 invalid bytes and 2 control characters, got %d and %d", bytes, controls)
+	}
+}
+
+func TestTokenOffsets(t *testing.T) {
+	for _, options := range []Option{Entities | Greek, NoOptions, EmitInvalid, ReplaceInvalid, FailInvalid} {
+		line := "x &amp; αβ\x00\x01 c\xffd"
+		in := make(chan string, 1)
+		in <- line
+		close(in)
+
+		for token := range Lex(in, 10, options) {
+			if token.IsEnd() {
+				continue
+			} else if line[token.Start:token.End] != token.Raw {
+				t.Errorf("%s: offsets %d-%d do not match %q", token.String(), token.Start, token.End, token.Raw)
+			}
+
+			// (the classes of the invalid input)
+			if invalid := invalidWidth(token.Raw) != 0; invalid && options == EmitInvalid && !token.IsInvalid() ||
+				invalid && options == FailInvalid && !token.IsError() ||
+				!invalid && (token.IsInvalid() || token.IsError()) {
+				t.Errorf("%d: unexpected class of %s (%q)", options, token.String(), token.Raw)
+			}
+		}
+	}
+}
+
+func TestDescribeOptions(t *testing.T) {
+	for options, expected := range map[Option]string{
+		NoOptions:      "",
+		Spaces | Greek: "spaces greek",
+		Entities | Dehyphenate | AttachHyphenated:           "entities dehyphenate attach-hyphenated",
+		SplitHyphenated | LexiconHyphenated:                 "split-hyphenated lexicon-hyphenated",
+		ReplaceInvalid | Markup | EmitInvalid | FailInvalid: "markup emit-invalid replace-invalid fail-invalid",
+	} {
+		if description := describeOptions(options); description != expected {
+			t.Errorf("%d: expected %q, got %q", options, expected, description)
+		}
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	if options, err := ParseOptions([]string{"entities", " Greek", "", "OCR"}); err != nil {
+		t.Error(err)
+	} else if options != Entities|Greek|OCR {
+		t.Errorf("unexpected options %d", options)
+	}
+
+	if _, err := ParseOptions([]string{"nope"}); err == nil {
+		t.Error("expected an error for an unknown option")
+	}
+}
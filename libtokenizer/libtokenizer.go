@@ -0,0 +1,242 @@
+/*
+A C shared library of the tokenizer, for embedding it in C, C++, or Python (ctypes),
+so they tokenize exactly like fnltok. Build the library and its header with:
+
+	go build -buildmode=c-shared -o libtokenizer.so ./libtokenizer
+
+This writes libtokenizer.so and libtokenizer.h, which declares:
+
+	int fnl_options(char *names);
+	int fnl_load_resource(char *kind, char *path);
+	fnl_token *fnl_tokenize(char *text, size_t length, int options, size_t *count);
+	void fnl_free_tokens(fnl_token *tokens, size_t count);
+	char *fnl_class_name(int tokenClass);
+
+The header compiles as C and as C++.
+The options that depend on resources (Truecase, Segment, Abbrevs,
+LexiconHyphenated, Dehyphenate, and OCR) use those loaded
+with fnl_load_resource, like the fnltok flags of the same names.
+The tokens returned by fnl_tokenize must be freed with fnl_free_tokens.
+See testdata/tokenize.c for an example.
+*/
+package main
+
+/*
+#include <limits.h>
+#include <stdlib.h>
+
+// a token, as returned by fnl_tokenize
+typedef struct {
+	int token_class; // the class of the token (see fnl_class_name)
+	char *value;     // the (normalized) value of the token, NUL-terminated
+	size_t length;   // the length of the value in bytes (without the NUL)
+	int start;       // the byte offset of the raw token in the text
+	int end;         // the byte offset after the raw token in the text
+} fnl_token;
+*/
+import "C"
+
+import (
+	"github.com/fnl/tokenizer"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// the resources loaded with fnl_load_resource (replaced on each load)
+var resources = &tokenizer.Resources{}
+var resourcesLock sync.RWMutex
+
+// the class names, allocated once and never freed
+var classNames []*C.char
+
+func init() {
+	for class := tokenizer.EndToken; class <= tokenizer.ErrorToken; class++ {
+		token := tokenizer.Token{Class: class}
+		classNames = append(classNames, C.CString(token.ClassName()))
+	}
+}
+
+// fnl_options returns the options named in a comma-separated list
+// ("entities,lowercase", case-insensitive), or -1 if a name is unknown.
+//
+//export fnl_options
+func fnl_options(names *C.char) C.int {
+	options, err := tokenizer.ParseOptions(strings.Split(C.GoString(names), ","))
+
+	if err != nil {
+		return -1
+	}
+
+	return C.int(options)
+}
+
+// readResource reads a resource file with the reader function
+func readResource[T any](path string, read func(io.Reader) (T, error)) (T, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		var none T
+		return none, err
+	}
+
+	defer file.Close()
+	return read(file)
+}
+
+// withAbbreviations combines the abbreviations loaded before (if any) with others
+func withAbbreviations(loaded, other *tokenizer.Abbreviations) *tokenizer.Abbreviations {
+	abbreviations := tokenizer.NewAbbreviations(nil)
+
+	if loaded != nil {
+		abbreviations.Merge(loaded)
+	}
+
+	abbreviations.Merge(other)
+	return abbreviations
+}
+
+// loadResource loads a resource of the kind from the path (see fnl_load_resource)
+// into a copy of the resources loaded before
+func loadResource(kind, path string) (*tokenizer.Resources, error) {
+	loaded := *resources
+	var err error
+
+	switch kind {
+	case "truecase":
+		loaded.Truecaser, err = readResource(path, tokenizer.ReadTruecaser)
+	case "cjk":
+		loaded.CJK, err = readResource(path, tokenizer.ReadDictionary)
+	case "thai":
+		loaded.Thai, err = readResource(path, tokenizer.ReadDictionary)
+	case "lexicon":
+		loaded.Lexicon, err = readResource(path, tokenizer.ReadDictionary)
+	case "abbrevs":
+		var abbreviations *tokenizer.Abbreviations
+
+		if words := tokenizer.BuiltinAbbreviations(path); words != nil {
+			abbreviations = tokenizer.NewAbbreviations(words)
+		} else {
+			abbreviations, err = readResource(path, tokenizer.ReadAbbreviations)
+		}
+		if err == nil {
+			loaded.Abbreviations = withAbbreviations(resources.Abbreviations, abbreviations)
+		}
+	case "punkt":
+		var model *tokenizer.Punkt
+
+		if model, err = readResource(path, tokenizer.ReadPunkt); err == nil {
+			loaded.Abbreviations = withAbbreviations(resources.Abbreviations, model.Abbreviations)
+		}
+	default:
+		err = os.ErrInvalid
+	}
+
+	return &loaded, err
+}
+
+// fnl_load_resource loads a resource file for the options that depend on it,
+// replacing any resource of the same kind loaded before, except that
+// abbreviations are added to those loaded before:
+// "truecase" (a truecasing model), "cjk" or "thai" (a segmentation dictionary),
+// "lexicon" (the known words), "abbrevs" (a file of abbreviations,
+// or the language of built-in ones, like "en"), or "punkt" (a Punkt model);
+// returns 0, or -1 if the kind is unknown or the file could not be read.
+//
+//export fnl_load_resource
+func fnl_load_resource(kind *C.char, path *C.char) C.int {
+	resourcesLock.Lock()
+	defer resourcesLock.Unlock()
+	loaded, err := loadResource(C.GoString(kind), C.GoString(path))
+
+	if err != nil {
+		return -1
+	}
+
+	resources = loaded
+	return 0
+}
+
+// fnl_tokenize lexes a text of the given length (in bytes) with the options
+// (and the resources loaded with fnl_load_resource),
+// and returns its tokens (or NULL, if there are none) and their count;
+// returns NULL (and lexes nothing) if count is NULL,
+// or with a count of 0 if the text is longer than INT_MAX bytes
+// (as the token offsets are ints);
+// the tokens must be freed with fnl_free_tokens.
+//
+//export fnl_tokenize
+func fnl_tokenize(text *C.char, length C.size_t, options C.int, count *C.size_t) *C.fnl_token {
+	if count == nil {
+		return nil
+	} else if length > C.INT_MAX {
+		*count = 0
+		return nil
+	}
+
+	var tokens []tokenizer.Token
+	input := make(chan string, 1)
+	input <- C.GoStringN(text, C.int(length))
+	close(input)
+	resourcesLock.RLock()
+	loaded := resources
+	resourcesLock.RUnlock()
+
+	for token := range tokenizer.LexWith(input, 100, tokenizer.Option(options), loaded) {
+		if !token.IsEnd() {
+			tokens = append(tokens, token)
+		}
+	}
+
+	*count = C.size_t(len(tokens))
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	array := (*C.fnl_token)(C.malloc(C.size_t(len(tokens)) * C.size_t(unsafe.Sizeof(C.fnl_token{}))))
+	results := unsafe.Slice(array, len(tokens))
+
+	for i, token := range tokens {
+		results[i] = C.fnl_token{
+			token_class: C.int(token.Class),
+			value:       C.CString(token.Value),
+			length:      C.size_t(len(token.Value)),
+			start:       C.int(token.Start),
+			end:         C.int(token.End),
+		}
+	}
+
+	return array
+}
+
+// fnl_free_tokens frees the tokens returned by fnl_tokenize.
+//
+//export fnl_free_tokens
+func fnl_free_tokens(tokens *C.fnl_token, count C.size_t) {
+	if tokens == nil {
+		return
+	}
+
+	for _, token := range unsafe.Slice(tokens, int(count)) {
+		C.free(unsafe.Pointer(token.value))
+	}
+
+	C.free(unsafe.Pointer(tokens))
+}
+
+// fnl_class_name returns the name of a token class ("Word", "Number", ...),
+// or NULL if there is no such class; the name must not be freed.
+//
+//export fnl_class_name
+func fnl_class_name(tokenClass C.int) *C.char {
+	if tokenClass < 0 || int(tokenClass) >= len(classNames) {
+		return nil
+	}
+
+	return classNames[tokenClass]
+}
+
+func main() {}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"github.com/fnl/tokenizer"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var libraryInputs = []string{
+	"Hello, world!",
+	"",
+	"Fish &amp; chips cost $3.50 (α-helix).",
+	"Ünïcödé  and\ttabs",
+}
+
+// lex formats the tokens of the inputs like testdata/tokenize.c
+func lex(inputs []string, options tokenizer.Option, resources *tokenizer.Resources) string {
+	var result strings.Builder
+	in := make(chan string, len(inputs))
+
+	for _, input := range inputs {
+		in <- input
+	}
+
+	close(in)
+
+	for token := range tokenizer.LexWith(in, 100, options, resources) {
+		if token.IsEnd() {
+			result.WriteString("\n")
+		} else {
+			fmt.Fprintf(&result, "%s\t%s\t%d\t%d\n", token.ClassName(), token.Value, token.Start, token.End)
+		}
+	}
+
+	return result.String()
+}
+
+// TestLibrary builds the shared library and runs testdata/tokenize.c with it,
+// compiled as C and as C++,
+// which must tokenize exactly like the tokenizer package does
+func TestLibrary(t *testing.T) {
+	dir := t.TempDir()
+	library := filepath.Join(dir, "libtokenizer.so")
+	lexicon := filepath.Join(dir, "lexicon.txt")
+	compiled := 0
+
+	if output, err := exec.Command("go", "build", "-buildmode=c-shared", "-o", library, ".").CombinedOutput(); err != nil {
+		t.Fatalf("building the library failed: %s\n%s", err, output)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "libtokenizer.h")); err != nil {
+		t.Fatalf("the header is missing: %s", err)
+	} else if err := os.WriteFile(lexicon, []byte("state\nof\nthe\nart\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for compiler, flags := range map[string][]string{
+		"gcc": {"-std=c99"},
+		"g++": {"-x", "c++", "-std=c++11"},
+	} {
+		path, err := exec.LookPath(compiler)
+
+		if err != nil {
+			continue
+		}
+
+		program := filepath.Join(dir, "tokenize-"+compiler)
+		args := append(flags, "-Wall", "-Werror", "-I", dir, "-o", program,
+			filepath.Join("testdata", "tokenize.c"), "-L", dir, "-ltokenizer")
+
+		if output, err := exec.Command(path, args...).CombinedOutput(); err != nil {
+			t.Fatalf("compiling testdata/tokenize.c with %s failed: %s\n%s", compiler, err, output)
+		}
+
+		runLibrary(t, dir, program, lexicon)
+		compiled++
+	}
+
+	if compiled == 0 {
+		t.Skip("neither gcc nor g++ found")
+	}
+}
+
+// runLibrary runs a compiled testdata/tokenize.c with several options and resources
+func runLibrary(t *testing.T, dir, program, lexicon string) {
+	inputs := append(libraryInputs, "state-of-the-art pre-processing")
+	withLexicon := &tokenizer.Resources{Lexicon: tokenizer.NewDictionary([]string{"state", "of", "the", "art"})}
+
+	for _, test := range []struct {
+		args      []string
+		options   tokenizer.Option
+		resources *tokenizer.Resources
+	}{
+		{nil, tokenizer.NoOptions, nil},
+		{[]string{"entities,greek,spaces"}, tokenizer.Entities | tokenizer.Greek | tokenizer.Spaces, nil},
+		{[]string{"All,Shapes"}, tokenizer.AllOptions | tokenizer.Shapes, nil},
+		{[]string{"lexicon-hyphenated"}, tokenizer.LexiconHyphenated, nil},
+		{[]string{"lexicon-hyphenated", "lexicon=" + lexicon}, tokenizer.LexiconHyphenated, withLexicon},
+	} {
+		run := exec.Command(program, test.args...)
+		run.Env = append(os.Environ(), "LD_LIBRARY_PATH="+dir)
+		run.Stdin = strings.NewReader(strings.Join(inputs, "\n") + "\n")
+		output, err := run.CombinedOutput()
+
+		if err != nil {
+			t.Fatalf("%s %q: running testdata/tokenize.c failed: %s\n%s", program, test.args, err, output)
+		} else if expected := lex(inputs, test.options, test.resources); string(output) != expected {
+			t.Errorf("%s %q: expected:\n%s\ngot:\n%s", program, test.args, expected, output)
+		}
+	}
+
+	for args, message := range map[string]string{
+		"nope":                    "unknown options",
+		"spaces lexicon=/no/such": "loading lexicon failed",
+		"spaces model=" + lexicon: "loading model failed",
+	} {
+		run := exec.Command(program, strings.Fields(args)...)
+		run.Env = append(os.Environ(), "LD_LIBRARY_PATH="+dir)
+
+		if output, err := run.CombinedOutput(); !strings.Contains(string(output), message) {
+			t.Errorf("%s %q: expected %q, got %v:\n%s", program, args, message, err, output)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Tokenizes each line of STDIN with the options named in the first argument
+ * and the resources given as KIND=PATH in the other arguments,
+ * writing a line "class<TAB>value<TAB>start<TAB>end" per token,
+ * and a blank line after the tokens of each line.
+ * Compiles as C and as C++.
+ */
+#include <limits.h>
+#include <stdio.h>
+#include <string.h>
+#include "libtokenizer.h"
+
+int main(int argc, char **argv) {
+    char line[4096];
+    char none[] = "";
+    int options = fnl_options(argc > 1 ? argv[1] : none);
+
+    if (options < 0) {
+        fprintf(stderr, "unknown options: %s\n", argv[1]);
+        return 1;
+    }
+
+    for (int i = 2; i < argc; i++) {
+        char *path = strchr(argv[i], '=');
+
+        if (path == NULL) {
+            fprintf(stderr, "expected KIND=PATH: %s\n", argv[i]);
+            return 1;
+        }
+
+        *path++ = '\0';
+
+        if (fnl_load_resource(argv[i], path) != 0) {
+            fprintf(stderr, "loading %s failed: %s\n", argv[i], path);
+            return 1;
+        }
+    }
+
+    if (fnl_tokenize(line, 0, options, NULL) != NULL) {
+        fprintf(stderr, "expected no tokens without a count\n");
+        return 1;
+    }
+
+    size_t skipped = 1;
+
+    if (fnl_tokenize(line, (size_t)INT_MAX + 1, options, &skipped) != NULL || skipped != 0) {
+        fprintf(stderr, "expected no tokens for a text longer than INT_MAX\n");
+        return 1;
+    }
+
+    while (fgets(line, sizeof(line), stdin) != NULL) {
+        size_t count;
+        size_t length = strcspn(line, "\n");
+        fnl_token *tokens = fnl_tokenize(line, length, options, &count);
+
+        for (size_t i = 0; i < count; i++) {
+            printf("%s\t%s\t%d\t%d\n", fnl_class_name(tokens[i].token_class),
+                   tokens[i].value, tokens[i].start, tokens[i].end);
+
+            if (strlen(tokens[i].value) != tokens[i].length) {
+                fprintf(stderr, "wrong length of %s\n", tokens[i].value);
+                return 1;
+            }
+        }
+
+        printf("\n");
+        fnl_free_tokens(tokens, count);
+    }
+
+    return 0;
+}
@@ -0,0 +1,246 @@
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// runes that might start Markdown syntax
+const markdownRunes string = "#*_~`>+-=[]!<"
+
+// regular expressions to match Markdown syntax at the start of a string
+var (
+	mdHeading   = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+	mdBullet    = regexp.MustCompile(`^[*+-][ \t]+`)
+	mdRule      = regexp.MustCompile(`^(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,}|(?:=[ \t]*){3,})(?:$|[\n\r])`)
+	mdFence     = regexp.MustCompile("^(```+|~~~+)[^\n\r]*(?:\r\n|[\n\r])?")
+	mdLink      = regexp.MustCompile(`^!?\[(?:[^\[\]]|\[[^\[\]]*\])*\]\([ \t]*<?[^\s()<>]+>?(?:[ \t]+(?:"[^"]*"|'[^']*'))?[ \t]*\)`)
+	mdTarget    = regexp.MustCompile(`^\]\([ \t]*<?([^\s()<>]+)>?(?:[ \t]+(?:"[^"]*"|'[^']*'))?[ \t]*\)`)
+	mdAutolink  = regexp.MustCompile(`^<((?:https?|ftp)://[^\s<>]+|mailto:[^\s<>]+)>`)
+	mdReference = regexp.MustCompile(`^\[[^\]]+\]:[ \t]*<?([^\s<>]+)>?`)
+)
+
+// lexMarkdown consumes Markdown syntax:
+// drops headings, rules, block quotes, list bullets, emphasis,
+// and link brackets;
+// emits code spans and blocks as CodeTokens
+// and link targets as URLTokens;
+// lexes anything else as a tag or symbol
+func lexMarkdown(l *lexer) stateFn {
+	r := l.lastRune()
+	text := l.buffer[l.start:]
+	lineStart := l.atLineStart()
+
+	if lineStart {
+		if m := mdFence.FindStringSubmatch(text); m != nil {
+			l.pos = l.start + len(m[0])
+			l.ignore()
+			l.fence = regexp.MustCompile("(?m)^[ \t]*" + regexp.QuoteMeta(m[1]) +
+				regexp.QuoteMeta(m[1][:1]) + "*[ \t\r]*$")
+			return lexCodeBlock
+		} else if m := mdRule.FindString(text); m != "" {
+			l.pos = l.start + len(strings.TrimRight(m, EOLMarkers))
+			l.ignore()
+			return lexText
+		} else if m := mdHeading.FindString(text); m != "" {
+			l.pos = l.start + len(m)
+			l.ignore()
+			return lexText
+		} else if m := mdBullet.FindString(text); m != "" {
+			l.pos = l.start + len(m)
+			l.ignore()
+			return lexText
+		} else if r == '>' {
+			l.ignore() // block quote
+			return lexText
+		} else if m := mdReference.FindStringSubmatchIndex(text); m != nil {
+			l.emitSpan(URLToken, l.start+m[2], l.start+m[3], l.start+m[1])
+			return lexText
+		}
+	}
+
+	switch {
+	case r == '`':
+		ticks := len(text) - len(strings.TrimLeft(text, "`"))
+
+		if end := closingTicks(text[ticks:], ticks); end != -1 {
+			code := text[ticks : ticks+end]
+			from := l.start + ticks + len(code) - len(strings.TrimLeft(code, " "))
+			to := l.start + ticks + len(strings.TrimRight(code, " "))
+
+			if from < to {
+				l.emitSpan(CodeToken, from, to, l.start+2*ticks+end)
+			} else {
+				l.pos = l.start + 2*ticks + end
+				l.ignore()
+			}
+
+			return lexText
+		}
+	case r == '*' || r == '_' || r == '~' && l.peek() == '~':
+		l.acceptAll(string(r))
+
+		if l.delimitsEmphasis(r) {
+			l.ignore() // emphasis (or strikethrough)
+			return lexText
+		}
+
+		l.width = len(string(r))
+		l.pos = l.start + l.width // a symbol
+	case (r == '[' || r == '!') && mdLink.MatchString(text):
+		l.ignore() // link (text is lexed as usual)
+		return lexText
+	case r == ']':
+		if m := mdTarget.FindStringSubmatchIndex(text); m != nil {
+			l.emitSpan(URLToken, l.start+m[2], l.start+m[3], l.start+m[1])
+			return lexText
+		}
+	case r == '<':
+		if m := mdAutolink.FindStringSubmatchIndex(text); m != nil {
+			l.emitSpan(URLToken, l.start+m[2], l.start+m[3], l.start+m[1])
+			return lexText
+		} else if l.stripsMarkup() {
+			return lexTag
+		}
+	}
+
+	return lexSymbol
+}
+
+// lexCodeBlock consumes a fenced code block up to its closing fence,
+// emitting the code as a CodeToken;
+// if the input ends before the closing fence,
+// the code block continues with the next input
+func lexCodeBlock(l *lexer) stateFn {
+	from, to, next := l.pos, len(l.buffer), len(l.buffer)
+
+	if end := l.fence.FindStringIndex(l.buffer[from:]); end != nil {
+		to, next = from+end[0], from+end[1]
+		l.fence = nil
+	}
+
+	to = from + len(strings.TrimRight(l.buffer[from:to], EOLMarkers))
+
+	if from < to {
+		l.emitSpan(CodeToken, from, to, next)
+	} else {
+		l.pos = next
+		l.ignore()
+	}
+
+	return lexText
+}
+
+// emitSpan ignores the buffer up to from,
+// emits the runes up to to as a token of the given class,
+// and ignores the rest up to next
+func (l *lexer) emitSpan(class TokenClass, from, to, next int) {
+	l.pos = from
+	l.ignore()
+	l.pos = to
+	l.emit(class)
+	l.pos = next
+	l.width = 0
+	l.ignore()
+}
+
+// delimitsEmphasis is true if the scanned run of delimiters
+// closes an emphasis (or strikethrough) opened before in the input,
+// or opens one that a later run of the same delimiters can close;
+// like CommonMark, a run can open if it is left-flanking
+// (not followed by a space, and not by punctuation unless preceded by a space or punctuation),
+// and close if it is right-flanking (vice versa),
+// while underscores within words neither open nor close
+func (l *lexer) delimitsEmphasis(delimiter rune) bool {
+	before, _ := utf8.DecodeLastRuneInString(l.buffer[:l.start])
+	after, _ := utf8.DecodeRuneInString(l.buffer[l.pos:])
+	left := !isBlank(after) && (!isPunctuation(after) || isBlank(before) || isPunctuation(before))
+	right := !isBlank(before) && (!isPunctuation(before) || isBlank(after) || isPunctuation(after))
+	opens, closes := left, right
+
+	if delimiter == '_' {
+		opens = left && (!right || isPunctuation(before))
+		closes = right && (!left || isPunctuation(after))
+	}
+
+	if i := strings.LastIndexByte(l.emphasis, byte(delimiter)); closes && i != -1 {
+		l.emphasis = l.emphasis[:i] + l.emphasis[i+1:]
+		return true
+	} else if opens && closesLater(l.buffer[l.pos:], delimiter) {
+		l.emphasis += string(delimiter)
+		return true
+	}
+
+	return false
+}
+
+// closesLater is true if a run of the delimiter in the text
+// follows a rune that is not a space
+func closesLater(text string, delimiter rune) bool {
+	for i := strings.IndexRune(text, delimiter); i > 0; {
+		if r, _ := utf8.DecodeLastRuneInString(text[:i]); !isBlank(r) && r != delimiter {
+			return true
+		}
+
+		next := strings.IndexRune(text[i+1:], delimiter)
+
+		if next == -1 {
+			break
+		}
+
+		i += 1 + next
+	}
+
+	return false
+}
+
+// true if the rune is a space or a linebreak (or no rune, at the start or end of the input)
+func isBlank(r rune) bool {
+	return r == utf8.RuneError || isSpace(r) || isEOL(r)
+}
+
+// true if the rune is punctuation or a symbol
+func isPunctuation(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// atLineStart is true if there are only spaces and tabs
+// between the current token and the start of the line
+func (l *lexer) atLineStart() bool {
+	for i := l.start; i > 0; {
+		r, w := utf8.DecodeLastRuneInString(l.buffer[:i])
+
+		if isEOL(r) {
+			return true
+		} else if r != ' ' && r != '\t' {
+			return false
+		}
+
+		i -= w
+	}
+
+	return true
+}
+
+// closingTicks returns the offset of the first run of exactly n backticks
+// in the text, or -1 if there is none
+func closingTicks(text string, n int) int {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], "`")
+
+		if i == -1 {
+			return -1
+		}
+
+		i += offset
+		run := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+
+		if run == n {
+			return i
+		}
+
+		offset = i + run
+	}
+}
@@ -0,0 +1,201 @@
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// a regular expression to match an HTML/XML tag, comment, or declaration
+// at the start of a string;
+// the first group matches the slash of closing tags,
+// the second group the tag name
+var tag = regexp.MustCompile(`^<(?:!--(?s:.*?)-->|[!?][^>]*>|(/?)([A-Za-z][A-Za-z0-9:-]*)(?:\s(?:[^>"']|"[^"]*"|'[^']*')*)?/?>)`)
+
+// a regular expression to match the start of an HTML/XML tag, comment, or declaration
+// that goes on in the next input;
+// the first group matches the slash of closing tags,
+// the second group the tag name
+var openTag = regexp.MustCompile(`^<(?:!--|[!?]|(/?)([A-Za-z][A-Za-z0-9:-]*)(?:\s|$))`)
+
+// the markup that continues from one input to the next
+type markupState struct {
+	comment  bool           // true if a comment goes on
+	tag      bool           // true if a tag goes on
+	name     string         // the (lowercase) name of the element of that tag
+	closing  bool           // true if that tag is a closing tag
+	quote    byte           // the quote of an attribute value that goes on in that tag
+	dropping *regexp.Regexp // the end tag of a script or style element that goes on
+}
+
+// true if a tag, comment, or dropped element goes on
+func (m *markupState) continues() bool {
+	return m.comment || m.tag || m.dropping != nil
+}
+
+// elements whose contents are dropped
+var droppedElements = map[string]*regexp.Regexp{
+	"script": regexp.MustCompile(`(?i)</script`),
+	"style":  regexp.MustCompile(`(?i)</style`),
+}
+
+// block-level elements that break lines
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"body": true, "br": true, "caption": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "head": true, "header": true, "hr": true, "html": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "td": true, "th": true, "title": true,
+	"tr": true, "ul": true,
+}
+
+// lexTag consumes an HTML/XML tag (or comment);
+// emits it as a TagToken (Tags option), as a LinebreakToken
+// (block-level elements, if linebreaks are emitted), or ignores it;
+// drops the contents of script and style elements;
+// if the tag goes on in the next input, consume the rest of this input;
+// if there is no tag, lex a symbol instead
+func lexTag(l *lexer) stateFn {
+	m := tag.FindStringSubmatchIndex(l.buffer[l.start:])
+
+	if m == nil {
+		return lexOpenTag
+	}
+
+	l.pos = l.start + m[1]
+	l.width = 0
+	name := ""
+	closing := m[2] != m[3]
+
+	if m[4] != -1 {
+		name = strings.ToLower(l.buffer[l.start+m[4] : l.start+m[5]])
+	}
+
+	l.emitTag(name)
+	l.dropElement(name, closing)
+	return lexText
+}
+
+// lexOpenTag consumes a tag (or comment) that goes on in the next input;
+// if there is no such tag, lex a symbol instead
+func lexOpenTag(l *lexer) stateFn {
+	rest := l.buffer[l.start:]
+	m := openTag.FindStringSubmatchIndex(rest)
+
+	if m == nil {
+		return lexSymbol
+	}
+
+	if strings.HasPrefix(rest, "<!--") {
+		l.markup = markupState{comment: true}
+	} else if end, quote := tagEnd(rest[m[1]:], 0); end == -1 {
+		l.markup = markupState{tag: true, closing: m[2] != m[3], quote: quote}
+
+		if m[4] != -1 {
+			l.markup.name = strings.ToLower(rest[m[4]:m[5]])
+		}
+	} else {
+		return lexSymbol // (a malformed tag)
+	}
+
+	l.pos = len(l.buffer)
+	l.width = 0
+	l.emitTag(l.markup.name)
+	return lexText
+}
+
+// lexMarkup continues a tag, comment, or script or style element
+// from the last input up to its end (or the end of this input)
+func lexMarkup(l *lexer) stateFn {
+	if l.markup.comment {
+		if end := strings.Index(l.buffer, "-->"); end != -1 {
+			l.pos = end + len("-->")
+			l.markup.comment = false
+		} else {
+			l.pos = len(l.buffer)
+		}
+
+		l.emitTag("")
+	} else if l.markup.tag {
+		end, quote := tagEnd(l.buffer, l.markup.quote)
+
+		if end != -1 {
+			l.pos = end
+			l.markup.tag = false
+		} else {
+			l.pos = len(l.buffer)
+			l.markup.quote = quote
+		}
+
+		l.emitTag("") // (a block-level tag broke the line where it started)
+
+		if !l.markup.tag {
+			l.dropElement(l.markup.name, l.markup.closing)
+		}
+	}
+
+	if l.markup.dropping != nil {
+		l.dropContents()
+	}
+
+	return lexText
+}
+
+// tagEnd returns the position after the ">" that ends a tag,
+// skipping over quoted attribute values,
+// starting inside an attribute value if a quote is given;
+// if the tag does not end, returns -1 and the quote of the value it ends in
+func tagEnd(s string, quote byte) (int, byte) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1, 0
+		}
+	}
+
+	return -1, quote
+}
+
+// emitTag emits the scanned tag (or the part of it in this input)
+// as a TagToken (Tags option),
+// as a LinebreakToken (block-level elements, if linebreaks are emitted),
+// or ignores it
+func (l *lexer) emitTag(name string) {
+	if l.emitsTags() && l.pos > l.start {
+		l.emit(TagToken)
+	} else if blockElements[name] && l.emitsLinebreaks() {
+		l.replace(l.start, l.pos, "\n")
+		l.pos = l.start + len("\n")
+		l.emit(LinebreakToken)
+	} else {
+		l.ignore()
+	}
+}
+
+// dropElement drops the contents of a script or style element after its start tag
+func (l *lexer) dropElement(name string, closing bool) {
+	if end, ok := droppedElements[name]; ok && !closing {
+		l.markup.dropping = end
+		l.dropContents()
+	}
+}
+
+// dropContents ignores the contents of a script or style element
+// up to its end tag (or the end of the input)
+func (l *lexer) dropContents() {
+	if idx := l.markup.dropping.FindStringIndex(l.buffer[l.pos:]); idx != nil {
+		l.pos += idx[0]
+		l.markup.dropping = nil
+	} else {
+		l.pos = len(l.buffer)
+	}
+
+	l.ignore()
+}
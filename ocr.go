@@ -0,0 +1,103 @@
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// the soft hyphen, which only marks a possible line break inside a word
+const softHyphen = '\u00AD'
+
+// mapping of the typographic Latin ligatures to their letters
+var ligatures = map[rune]string{
+	'\uFB00': "ff",
+	'\uFB01': "fi",
+	'\uFB02': "fl",
+	'\uFB03': "ffi",
+	'\uFB04': "ffl",
+	'\uFB05': "st",
+	'\uFB06': "st",
+}
+
+// mapping of doubled quotes, as OCR or plain-text conventions produce them,
+// to the double quotes they stand for
+var doubledQuotes = map[string]string{
+	",,": "\u201E",
+	"``": "\u201C",
+	"''": "\u201D",
+}
+
+// a regular expression matching spaces (but not linebreaks) between words
+var splitWordSpace = regexp.MustCompile(`^[ \t]+`)
+
+// cleanOCR replaces the rune just decoded at the current position
+// (but not yet scanned) if it is an OCR or PDF extraction artifact:
+// resolves ligatures, joins doubled quotes,
+// drops soft hyphens inside words, and turns soft hyphens
+// at the end of a line into hyphens (see Dehyphenate);
+// returns the rune to scan instead and sets its width
+func (l *lexer) cleanOCR(r rune) rune {
+	switch {
+	case r == softHyphen:
+		rest := strings.TrimLeftFunc(l.buffer[l.pos+l.width:], isSpace)
+
+		if r, _ := utf8.DecodeRuneInString(rest); rest == "" || isEOL(r) {
+			l.replace(l.pos, l.pos+l.width, "-")
+			l.width = len("-")
+			return '-'
+		}
+
+		l.replace(l.pos, l.pos+l.width, "")
+
+		if l.pos == len(l.buffer) {
+			l.width = 0
+			return 0
+		}
+
+		r, l.width = utf8.DecodeRuneInString(l.buffer[l.pos:])
+		return l.cleanOCR(r)
+	case ligatures[r] != "":
+		l.replace(l.pos, l.pos+l.width, ligatures[r])
+		r, l.width = utf8.DecodeRuneInString(ligatures[r])
+	case r == ',' || r == '`' || r == '\'':
+		end := l.pos + 2*l.width
+
+		if end <= len(l.buffer) && doubledQuotes[l.buffer[l.pos:end]] != "" {
+			quote := doubledQuotes[l.buffer[l.pos:end]]
+			l.replace(l.pos, end, quote)
+			r, l.width = utf8.DecodeRuneInString(quote)
+		}
+	}
+
+	return r
+}
+
+// joinSplitWord removes the spaces after the scanned word
+// if they split a word in two, which is the case
+// if the joined word is in the Lexicon, but not both of its parts
+// ("infor mation", but not "in formation");
+// returns false if the word was not joined
+func (l *lexer) joinSplitWord() bool {
+	if l.resources.Lexicon == nil {
+		return false
+	}
+
+	head := l.buffer[l.start:l.pos]
+	spaces := splitWordSpace.FindString(l.buffer[l.pos:])
+	tail := l.buffer[l.pos+len(spaces):]
+
+	if end := strings.IndexFunc(tail, func(r rune) bool { return !unicode.IsLetter(r) }); end != -1 {
+		tail = tail[:end]
+	}
+
+	if spaces == "" || tail == "" || !l.inLexicon(head+tail) ||
+		l.inLexicon(head) && l.inLexicon(tail) {
+		return false
+	}
+
+	l.replace(l.pos, l.pos+len(spaces), "")
+	l.width = 0
+	return true
+}
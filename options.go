@@ -0,0 +1,84 @@
+package tokenizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// the options, by name (see ParseOptions)
+var optionNames = map[string]Option{
+	"spaces":             Spaces,
+	"linebreaks":         Linebreaks,
+	"entities":           Entities,
+	"quotes":             Quotes,
+	"lowercase":          Lowercase,
+	"greek":              Greek,
+	"hyphens":            Hyphens,
+	"all":                AllOptions,
+	"bio":                Bio,
+	"shapes":             Shapes,
+	"truecase":           Truecase,
+	"markup":             Markup,
+	"tags":               Tags,
+	"markdown":           Markdown,
+	"social":             Social,
+	"elongations":        Elongations,
+	"segment":            Segment,
+	"scripts":            Scripts,
+	"uax29":              UAX29,
+	"abbrevs":            Abbrevs,
+	"dehyphenate":        Dehyphenate,
+	"ocr":                OCR,
+	"split-hyphenated":   SplitHyphenated,
+	"attach-hyphenated":  AttachHyphenated,
+	"lexicon-hyphenated": LexiconHyphenated,
+	"emit-invalid":       EmitInvalid,
+	"replace-invalid":    ReplaceInvalid,
+	"fail-invalid":       FailInvalid,
+}
+
+// describeOptions lists the names of the options set,
+// in the order of their bits ("spaces entities split-hyphenated")
+func describeOptions(options Option) string {
+	var names []string
+
+	for option := Option(1); option > 0 && option <= options; option <<= 1 {
+		for name, named := range optionNames {
+			if named == option && options&option != 0 {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return strings.Join(names, " ")
+}
+
+// OptionNames returns the sorted names of the options ParseOptions knows.
+func OptionNames() []string {
+	var names []string
+
+	for name := range optionNames {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ParseOptions combines the named options (case-insensitive, see OptionNames).
+func ParseOptions(names []string) (Option, error) {
+	var options Option
+
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			continue
+		} else if option, ok := optionNames[name]; ok {
+			options |= option
+		} else {
+			return options, fmt.Errorf("unknown option %q", name)
+		}
+	}
+
+	return options, nil
+}
@@ -0,0 +1,336 @@
+package tokenizer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Punkt model holds the abbreviations, collocations, and frequent sentence starters
+// learned without supervision by TrainPunkt (Kiss and Strunk, 2006).
+//
+// The lexer uses the Abbreviations (see Resources);
+// the collocations and sentence starters help sentence splitters
+// to decide if a period after an abbreviation, number, or initial ends a sentence.
+type Punkt struct {
+	Abbreviations *Abbreviations     // the abbreviations learned
+	collocations  map[[2]string]bool // pairs of types that span a period ("jan. ##number##")
+	starters      map[string]bool    // types that frequently start sentences
+}
+
+// the Punkt thresholds, as proposed by Kiss and Strunk
+const (
+	punktAbbreviation = 0.3  // the minimal score of abbreviations
+	punktCollocation  = 7.88 // the minimal log-likelihood of collocations
+	punktStarter      = 30.0 // the minimal log-likelihood of sentence starters
+)
+
+// the type that represents all numbers
+const punktNumber = "##number##"
+
+// the kinds of entries in a Punkt model file
+const (
+	punktAbbreviationEntry = "abbreviation"
+	punktCollocationEntry  = "collocation"
+	punktStarterEntry      = "starter"
+)
+
+// a token of the training text, reduced to its type
+type punktToken struct {
+	typ       string // the lowercased value, without the final period
+	period    bool   // true if the token ends with a period
+	word      bool   // true if the token is a word or number
+	sentbreak bool   // true if the token ends a sentence (set after abbreviation detection)
+}
+
+// TrainPunkt learns abbreviations, collocations, and sentence starters
+// from a stream of tokens, until the token channel is closed.
+//
+// A period following a word or number is treated as part of that word,
+// so the token stream can be produced with or without the Abbrevs option.
+// The statistics need a lot of text (thousands of sentences);
+// on small texts, any word seen only before periods looks like an abbreviation.
+func TrainPunkt(tokens chan Token) *Punkt {
+	var text []punktToken
+
+	for token := range tokens {
+		switch {
+		case token.IsWord() || token.IsNumber():
+			typ := strings.ToLower(token.Value)
+
+			if token.IsNumber() {
+				typ = punktNumber
+			}
+
+			text = append(text, punktToken{
+				typ:    strings.TrimSuffix(typ, "."),
+				period: strings.HasSuffix(typ, "."),
+				word:   true,
+			})
+		case token.IsSymbol():
+			if n := len(text); token.Value == "." && n > 0 && text[n-1].word && !text[n-1].period {
+				text[n-1].period = true
+			} else {
+				text = append(text, punktToken{typ: token.Value})
+			}
+		case token.IsEnd() || token.IsSpace() || token.IsLinebreak():
+			// ignore
+		default:
+			text = append(text, punktToken{typ: strings.ToLower(token.Value)})
+		}
+	}
+
+	p := &Punkt{
+		Abbreviations: NewAbbreviations(nil),
+		collocations:  make(map[[2]string]bool),
+		starters:      make(map[string]bool),
+	}
+	p.findAbbreviations(text)
+	p.findSentenceBreaks(text)
+	p.findStarters(text)
+	p.findCollocations(text)
+	return p
+}
+
+// findAbbreviations scores all types that occur with a final period
+// by their log-likelihood to always end with one,
+// weighted by their length and internal periods
+func (p *Punkt) findAbbreviations(text []punktToken) {
+	withPeriod := make(map[string]int)
+	withoutPeriod := make(map[string]int)
+	periods := 0
+
+	for _, t := range text {
+		if t.period {
+			withPeriod[t.typ]++
+			periods++
+		} else {
+			withoutPeriod[t.typ]++
+		}
+	}
+
+	for typ, count := range withPeriod {
+		if typ == punktNumber || !strings.ContainsFunc(typ, unicode.IsLetter) {
+			continue
+		}
+
+		inner := strings.Count(typ, ".")
+		length := utf8.RuneCountInString(typ) - inner
+		ll := dunningLogLikelihood(count+withoutPeriod[typ], periods, count, len(text))
+		score := ll * math.Exp(-float64(length)) * float64(inner+1) *
+			math.Pow(float64(length), -float64(withoutPeriod[typ]))
+
+		if score >= punktAbbreviation {
+			p.Abbreviations.Add(typ)
+		}
+	}
+}
+
+// findSentenceBreaks marks the tokens that end a sentence:
+// words with a period that are no abbreviations, and sentence terminals
+func (p *Punkt) findSentenceBreaks(text []punktToken) {
+	for i := range text {
+		t := &text[i]
+		t.sentbreak = t.period && !p.Abbreviations.Contains(t.typ) ||
+			!t.word && t.typ != "" && strings.Trim(t.typ, sentenceTerminals) == ""
+	}
+}
+
+// findStarters collects the types that occur significantly more often
+// after a sentence break than elsewhere
+func (p *Punkt) findStarters(text []punktToken) {
+	counts := make(map[string]int)
+	starts := make(map[string]int)
+	breaks := 0
+
+	for i, t := range text {
+		counts[t.typ]++
+
+		if t.sentbreak {
+			breaks++
+
+			if i+1 < len(text) && text[i+1].word && text[i+1].typ != punktNumber {
+				starts[text[i+1].typ]++
+			}
+		}
+	}
+
+	for typ, count := range starts {
+		ll := collocationLogLikelihood(breaks, counts[typ], count, len(text))
+
+		if ll >= punktStarter && float64(len(text))/float64(breaks) > float64(counts[typ])/float64(count) {
+			p.starters[typ] = true
+		}
+	}
+}
+
+// findCollocations collects the pairs of a number or initial with a period
+// and the following word that occur significantly often together
+func (p *Punkt) findCollocations(text []punktToken) {
+	counts := make(map[string]int)
+	pairs := make(map[[2]string]int)
+
+	for i, t := range text {
+		counts[t.typ]++
+
+		if t.period && (t.typ == punktNumber || isInitials(strings.ToUpper(t.typ))) &&
+			i+1 < len(text) && text[i+1].word {
+			pairs[[2]string{t.typ, text[i+1].typ}]++
+		}
+	}
+
+	for pair, count := range pairs {
+		if count <= 1 {
+			continue
+		}
+
+		first, second := counts[pair[0]], counts[pair[1]]
+		ll := collocationLogLikelihood(first, second, count, len(text))
+
+		if ll >= punktCollocation && float64(len(text))/float64(first) > float64(second)/float64(count) {
+			p.collocations[pair] = true
+		}
+	}
+}
+
+// dunningLogLikelihood compares the likelihood that a type (a)
+// occurs with a period (b) as often as all tokens
+// against the likelihood that it (almost) always does
+func dunningLogLikelihood(a, b, ab, n int) float64 {
+	null := logBinomial(ab, a, float64(b)/float64(n))
+	alt := logBinomial(ab, a, 0.99)
+	return -2 * (null - alt)
+}
+
+// collocationLogLikelihood compares the likelihood that b follows a
+// as often as it occurs anywhere else
+// against the likelihood that it follows a more (or less) often
+func collocationLogLikelihood(a, b, ab, n int) float64 {
+	p := float64(b) / float64(n)
+	p1 := float64(ab) / float64(a)
+	p2 := float64(b-ab) / float64(n-a)
+	s1 := logBinomial(ab, a, p)
+	s2 := logBinomial(b-ab, n-a, p)
+	s3, s4 := 0.0, 0.0
+
+	if a != ab {
+		s3 = logBinomial(ab, a, p1)
+	}
+
+	if b != ab {
+		s4 = logBinomial(b-ab, n-a, p2)
+	}
+
+	return -2 * (s1 + s2 - s3 - s4)
+}
+
+// the log-likelihood of k successes in n trials with probability p
+// (without the binomial coefficient);
+// zero if p is no probability or rules out the successes or failures
+// (as when all tokens are of a type), so the likelihood ratios stay finite
+func logBinomial(k, n int, p float64) float64 {
+	if math.IsNaN(p) || p < 0 || p > 1 || k > 0 && p == 0 || n > k && p == 1 {
+		return 0
+	}
+
+	l := 0.0
+
+	if k > 0 {
+		l += float64(k) * math.Log(p)
+	}
+
+	if n > k {
+		l += float64(n-k) * math.Log(1-p)
+	}
+
+	return l
+}
+
+// ReadPunkt loads a Punkt model written by WriteTo.
+func ReadPunkt(r io.Reader) (*Punkt, error) {
+	p := &Punkt{
+		Abbreviations: NewAbbreviations(nil),
+		collocations:  make(map[[2]string]bool),
+		starters:      make(map[string]bool),
+	}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), "\t")
+
+		switch {
+		case len(fields) == 1 && fields[0] == "":
+			// skip empty lines
+		case len(fields) == 2 && fields[0] == punktAbbreviationEntry:
+			p.Abbreviations.Add(fields[1])
+		case len(fields) == 3 && fields[0] == punktCollocationEntry:
+			p.collocations[[2]string{fields[1], fields[2]}] = true
+		case len(fields) == 2 && fields[0] == punktStarterEntry:
+			p.starters[fields[1]] = true
+		default:
+			return nil, fmt.Errorf("line %d: malformed Punkt model entry %q", line, scanner.Text())
+		}
+	}
+
+	return p, scanner.Err()
+}
+
+// WriteTo writes the Punkt model, one tab-separated entry per line:
+// the kind of entry ("abbreviation", "collocation", or "starter"),
+// followed by the type (or the two types of a collocation).
+func (p *Punkt) WriteTo(w io.Writer) (int64, error) {
+	var lines []string
+
+	for typ := range p.Abbreviations.words {
+		lines = append(lines, punktAbbreviationEntry+"\t"+typ)
+	}
+
+	for pair := range p.collocations {
+		lines = append(lines, punktCollocationEntry+"\t"+pair[0]+"\t"+pair[1])
+	}
+
+	for typ := range p.starters {
+		lines = append(lines, punktStarterEntry+"\t"+typ)
+	}
+
+	sort.Strings(lines)
+	var total int64
+
+	for _, line := range lines {
+		n, err := fmt.Fprintln(w, line)
+		total += int64(n)
+
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// the Punkt type of a token
+func punktType(token Token) string {
+	if token.IsNumber() {
+		return punktNumber
+	}
+
+	return strings.ToLower(strings.TrimSuffix(token.Value, "."))
+}
+
+// IsCollocation is true if a token with a period (a number or initial)
+// and the following token form a collocation,
+// so the period does not end the sentence ("Jan. 5", "5. Jan").
+func (p *Punkt) IsCollocation(first, second Token) bool {
+	return p.collocations[[2]string{punktType(first), punktType(second)}]
+}
+
+// IsSentenceStarter is true if the token frequently starts sentences,
+// so a period before it likely ends a sentence, even after an abbreviation.
+func (p *Punkt) IsSentenceStarter(token Token) bool {
+	return p.starters[punktType(token)]
+}
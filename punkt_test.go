@@ -0,0 +1,142 @@
+package tokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+var punktCorpus = []string{
+	"Dr. Smith met Mr. Jones on Jan. 5. However, it rained.",
+	"The report was late. However, nobody cared.",
+	"We bought apples, pears, etc. and ate them.",
+	"Prof. Miller and Dr. Brown arrived on Jan. 5 and left.",
+	"They talked. However, the weather was bad.",
+	"She said it was fine. The talks went on.",
+	"J. Smith wrote to Dr. Jones. However, no reply came.",
+	"Mr. Brown met Prof. Miller. Then they left.",
+	"The late train was bad for the late guests.",
+	"A bad day and a bad night were late news.",
+}
+
+func trainTestPunkt() *Punkt {
+	in := make(chan string, len(punktCorpus)*20)
+
+	for i := 0; i < 20; i++ {
+		for _, line := range punktCorpus {
+			in <- line
+		}
+	}
+
+	close(in)
+	return TrainPunkt(Lex(in, 100, NoOptions))
+}
+
+func TestTrainPunkt(t *testing.T) {
+	model := trainTestPunkt()
+
+	for word, expected := range map[string]bool{
+		"dr": true, "mr": true, "prof": true, "etc": true,
+		"late": false, "bad": false, "however": false,
+	} {
+		if model.Abbreviations.Contains(word) != expected {
+			t.Errorf("abbreviation %q: expected %v", word, expected)
+		}
+	}
+
+	if !model.IsSentenceStarter(Token{Class: WordToken, Value: "However"}) {
+		t.Error("expected However to be a sentence starter")
+	}
+
+	if model.IsSentenceStarter(Token{Class: WordToken, Value: "Smith"}) {
+		t.Error("expected Smith not to be a sentence starter")
+	}
+}
+
+func TestPunktReadWrite(t *testing.T) {
+	var buffer bytes.Buffer
+	model := trainTestPunkt()
+
+	if _, err := model.WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buffer.String(), "abbreviation\tdr\n") {
+		t.Errorf("model lacks dr:\n%s", buffer.String())
+	}
+
+	loaded, err := ReadPunkt(strings.NewReader(buffer.String()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded bytes.Buffer
+
+	if _, err = loaded.WriteTo(&reloaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded.String() != buffer.String() {
+		t.Errorf("expected\n%s\ngot\n%s", buffer.String(), reloaded.String())
+	}
+}
+
+func TestReadPunktCollocations(t *testing.T) {
+	model, err := ReadPunkt(strings.NewReader("collocation\tjan\t##number##\n\nstarter\tthen\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jan := Token{Class: WordToken, Value: "Jan."}
+	five := Token{Class: NumberToken, Value: "5"}
+
+	if !model.IsCollocation(jan, five) || model.IsCollocation(five, jan) {
+		t.Error("expected only Jan. 5 to be a collocation")
+	}
+
+	if _, err = ReadPunkt(strings.NewReader("abbreviation\n")); err == nil {
+		t.Error("expected an error for a malformed entry")
+	}
+
+	if !model.IsSentenceStarter(Token{Class: WordToken, Value: "Then"}) {
+		t.Error("expected Then to be a sentence starter")
+	}
+}
+
+func TestPunktAbbrevs(t *testing.T) {
+	in := make(chan string, 1)
+	in <- "Dr. Smith is late."
+	close(in)
+	resources := &Resources{Abbreviations: trainTestPunkt().Abbreviations}
+	expected := []string{"Dr.", "Smith", "is", "late", ".", ""}
+	var values []string
+
+	for token := range LexWith(in, 10, Abbrevs, resources) {
+		values = append(values, token.Value)
+	}
+
+	if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", expected) {
+		t.Errorf("expected %q, got %q", expected, values)
+	}
+}
+
+func TestPunktLogLikelihoods(t *testing.T) {
+	for n := 1; n <= 5; n++ {
+		for a := 0; a <= n; a++ {
+			for b := 0; b <= n; b++ {
+				for ab := 0; ab <= a && ab <= b; ab++ {
+					if ll := collocationLogLikelihood(a, b, ab, n); math.IsNaN(ll) || math.IsInf(ll, 0) {
+						t.Errorf("collocation a=%d b=%d ab=%d n=%d: %f", a, b, ab, n, ll)
+					}
+					if ll := dunningLogLikelihood(a, b, ab, n); math.IsNaN(ll) || math.IsInf(ll, 0) {
+						t.Errorf("dunning a=%d b=%d ab=%d n=%d: %f", a, b, ab, n, ll)
+					}
+				}
+			}
+		}
+	}
+}
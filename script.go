@@ -0,0 +1,129 @@
+package tokenizer
+
+import (
+	"sort"
+	"unicode"
+)
+
+// the names of the scripts that do not change the script of a word
+const (
+	commonScript    = "Common"
+	inheritedScript = "Inherited"
+)
+
+// the scripts tested first, before all others
+var frequentScripts = []string{
+	"Latin", commonScript, inheritedScript, "Greek", "Cyrillic",
+	"Han", "Arabic", "Hebrew", "Devanagari", "Hiragana", "Katakana",
+	"Hangul", "Thai",
+}
+
+// all other scripts, sorted by name
+var otherScripts []string
+
+func init() {
+	frequent := make(map[string]bool)
+
+	for _, name := range frequentScripts {
+		frequent[name] = true
+	}
+
+	for name := range unicode.Scripts {
+		if !frequent[name] {
+			otherScripts = append(otherScripts, name)
+		}
+	}
+
+	sort.Strings(otherScripts)
+}
+
+// scriptOf returns the name of the Unicode script of a rune
+func scriptOf(r rune) string {
+	if r < 0x80 {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' {
+			return "Latin"
+		}
+
+		return commonScript
+	}
+
+	for _, name := range frequentScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+
+	for _, name := range otherScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+
+	return commonScript
+}
+
+// true if the script does not change the script of a word
+func isCommonScript(script string) bool {
+	return script == commonScript || script == inheritedScript
+}
+
+// dominantScript returns the name of the script most runes of a value belong to
+// (the first one, if there is a tie), ignoring the Common and Inherited scripts;
+// if there are only such runes, returns "Common"
+func dominantScript(value string) string {
+	var scripts []string
+	var counts []int
+	best := -1
+
+	for _, r := range value {
+		script := scriptOf(r)
+
+		if isCommonScript(script) {
+			continue
+		}
+
+		i := 0
+
+		for i < len(scripts) && scripts[i] != script {
+			i++
+		}
+
+		if i == len(scripts) {
+			scripts = append(scripts, script)
+			counts = append(counts, 0)
+		}
+
+		counts[i]++
+
+		if best == -1 || counts[i] > counts[best] {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return commonScript
+	}
+
+	return scripts[best]
+}
+
+// changesScript is true if the rune's script differs
+// from the script of the current word;
+// otherwise, records the rune's script as the word's script
+//
+// If the lexer expands Greek letters, Greek and Latin do not differ.
+func (l *lexer) changesScript(r rune) bool {
+	script := scriptOf(r)
+
+	if isCommonScript(script) {
+		return false
+	} else if l.script == "" {
+		l.script = script
+		return false
+	} else if l.expandsGreek() &&
+		(script == "Greek" && l.script == "Latin" || script == "Latin" && l.script == "Greek") {
+		return false
+	}
+
+	return script != l.script
+}
@@ -0,0 +1,197 @@
+package tokenizer
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Dictionary is a set of words used to segment scripts
+// that do not separate words with spaces.
+type Dictionary struct {
+	words  map[string]bool // the known words
+	maxLen int             // the length of the longest word (in runes)
+}
+
+// the kinds of script runs that get segmented
+const (
+	noSegment   = iota // not segmented
+	cjkSegment         // Han ideographs and Japanese kana
+	thaiSegment        // Thai
+)
+
+// the prolonged sound mark, used in kana words ("コーヒー")
+const prolongedSoundMark = 'ー'
+
+// NewDictionary creates a dictionary from a list of words.
+func NewDictionary(words []string) *Dictionary {
+	d := &Dictionary{words: make(map[string]bool, len(words))}
+
+	for _, w := range words {
+		d.add(w)
+	}
+
+	return d
+}
+
+// ReadDictionary loads a dictionary with one word per line;
+// only the first field of each line is used,
+// so that word lists with frequencies or tags can be used as is.
+func ReadDictionary(r io.Reader) (*Dictionary, error) {
+	d := &Dictionary{words: make(map[string]bool)}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			d.add(fields[0])
+		}
+	}
+
+	return d, scanner.Err()
+}
+
+// add a word to the dictionary
+func (d *Dictionary) add(word string) {
+	d.words[word] = true
+
+	if n := utf8.RuneCountInString(word); n > d.maxLen {
+		d.maxLen = n
+	}
+}
+
+// true if the dictionary contains the word
+func (d *Dictionary) Contains(word string) bool {
+	return d.words[word]
+}
+
+// longestMatch returns the length (in bytes)
+// of the longest dictionary word at the start of the text
+// (or zero if there is none)
+func (d *Dictionary) longestMatch(text string) int {
+	longest, n := 0, 0
+
+	for i, r := range text {
+		if n == d.maxLen {
+			break
+		}
+
+		n++
+
+		if end := i + utf8.RuneLen(r); d.words[text[:end]] {
+			longest = end
+		}
+	}
+
+	return longest
+}
+
+// segmentClass returns the kind of script run a rune belongs to
+func segmentClass(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == prolongedSoundMark:
+		return cjkSegment
+	case unicode.Is(unicode.Thai, r):
+		return thaiSegment
+	}
+
+	return noSegment
+}
+
+// lexSegment consumes a run of Han, kana, or Thai runes
+// and segments it into words:
+// with a dictionary (see Resources), by longest match;
+// otherwise, Han ideographs become single words,
+// kana runs of one script become a word,
+// and Thai runs stay whole
+func lexSegment(l *lexer) stateFn {
+	class := segmentClass(l.scan())
+	dictionary := l.resources.CJK
+	end := l.start
+	unmatched := false
+
+	if class == thaiSegment {
+		dictionary = l.resources.Thai
+	}
+
+	for end < len(l.buffer) {
+		r, w := utf8.DecodeRuneInString(l.buffer[end:])
+
+		if segmentClass(r) != class {
+			break
+		}
+
+		end += w
+	}
+
+	for l.pos = l.start; l.pos < end; {
+		text := l.buffer[l.pos:end]
+		n := 0
+
+		if dictionary != nil {
+			n = dictionary.longestMatch(text)
+		}
+
+		if n > 0 {
+			if unmatched {
+				l.emit(WordToken)
+				unmatched = false
+			}
+
+			l.pos += n
+			l.emit(WordToken)
+		} else if class == thaiSegment {
+			l.pos += thaiCluster(text)
+			unmatched = true
+		} else {
+			l.pos += cjkUnit(text)
+			l.emit(WordToken)
+		}
+	}
+
+	if unmatched {
+		l.emit(WordToken)
+	}
+
+	l.width = 0
+	return lexText
+}
+
+// cjkUnit returns the length of a single Han ideograph
+// or of the run of kana of the same script at the start of the text
+func cjkUnit(text string) int {
+	r, w := utf8.DecodeRuneInString(text)
+
+	if unicode.Is(unicode.Han, r) {
+		return w
+	}
+
+	script := unicode.Hiragana
+
+	if unicode.Is(unicode.Katakana, r) {
+		script = unicode.Katakana
+	}
+
+	for i, r := range text[w:] {
+		if !unicode.Is(script, r) && r != prolongedSoundMark {
+			return w + i
+		}
+	}
+
+	return len(text)
+}
+
+// thaiCluster returns the length of the first Thai letter
+// and its combining vowel and tone marks
+func thaiCluster(text string) int {
+	_, w := utf8.DecodeRuneInString(text)
+
+	for i, r := range text[w:] {
+		if !unicode.Is(unicode.Mn, r) {
+			return w + i
+		}
+	}
+
+	return len(text)
+}
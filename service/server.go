@@ -0,0 +1,160 @@
+// Package service provides the tokenizer as a bidirectional-streaming gRPC service
+// (see tokenizer.proto): clients stream documents in and receive their tokens
+// back in batches, as the lexer's input and output channels would.
+package service
+
+//go:generate buf generate
+
+import (
+	"github.com/fnl/tokenizer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+)
+
+// the number of tokens per batch, at most
+const batchSize = 1000
+
+// A Server lexes the documents of each stream with a lexer of its own.
+type Server struct {
+	UnimplementedTokenizerServer
+	options   tokenizer.Option
+	resources *tokenizer.Resources
+	slots     chan struct{}
+}
+
+// NewServer creates a server that lexes with the options and resources,
+// unless a stream asks for other options;
+// a stream waits for a free slot before its lexer starts,
+// and frees it when it ends, so the capacity of the slots
+// (that might be shared with other services)
+// bounds the number of lexers at once (unbounded if nil).
+func NewServer(options tokenizer.Option, resources *tokenizer.Resources, slots chan struct{}) *Server {
+	if resources == nil {
+		resources = &tokenizer.Resources{}
+	}
+
+	return &Server{options: options, resources: resources, slots: slots}
+}
+
+// Tokenize lexes the documents of a stream.
+//
+// The documents are received only as fast as the lexer reads them,
+// and the lexer only runs ahead of the batches sent by its output buffer,
+// so a slow client holds the lexer back (and the other way round).
+// Each document's tokens are sent in batches of its own,
+// even if the lexer's state continues across documents (see Lex).
+func (s *Server) Tokenize(stream Tokenizer_TokenizeServer) error {
+	first, err := stream.Recv()
+
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	options := s.options
+
+	if len(first.Options) > 0 {
+		if options, err = tokenizer.ParseOptions(first.Options); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+
+	input := make(chan string)
+	received := make(chan error, 1)
+	tokens := tokenizer.LexWith(input, batchSize, options, s.resources)
+
+	go func() {
+		defer close(input)
+		request := first
+
+		for {
+			select {
+			case input <- request.GetText():
+			case <-stream.Context().Done():
+				received <- stream.Context().Err()
+				return
+			}
+
+			var err error
+
+			if request, err = stream.Recv(); err == io.EOF {
+				received <- nil
+				return
+			} else if err != nil {
+				received <- err
+				return
+			}
+		}
+	}()
+
+	if err := sendBatches(stream, tokens, options); err != nil {
+		return err
+	}
+
+	return <-received
+}
+
+// sendBatches sends the tokens of each document in batches;
+// after a failure, it drains the tokens, so the lexer can shut down
+func sendBatches(stream Tokenizer_TokenizeServer, tokens chan tokenizer.Token, options tokenizer.Option) error {
+	batch := &TokenBatch{}
+	var failed error
+
+	for token := range tokens {
+		if failed != nil {
+			continue
+		}
+
+		if !token.IsEnd() {
+			batch.Tokens = append(batch.Tokens, newToken(token, options))
+
+			if len(batch.Tokens) < batchSize {
+				continue
+			}
+		} else {
+			batch.Last = true
+		}
+
+		if failed = stream.Send(batch); failed == nil {
+			batch = &TokenBatch{Document: batch.Document}
+
+			if token.IsEnd() {
+				batch.Document++
+			}
+		}
+	}
+
+	return failed
+}
+
+// newToken converts a token of the lexer
+func newToken(token tokenizer.Token, options tokenizer.Option) *Token {
+	t := &Token{
+		Class: token.ClassName(),
+		Value: token.Value,
+		Raw:   token.Raw,
+		Start: int32(token.Start),
+		End:   int32(token.End),
+	}
+
+	if options&tokenizer.Shapes != 0 {
+		t.Shape = token.Shape
+		t.Features = token.Features.String()
+	}
+	if options&tokenizer.Scripts != 0 {
+		t.Script = token.Script
+	}
+
+	return t
+}
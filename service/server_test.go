@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"github.com/fnl/tokenizer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient serves a Server in-process and connects a client to it
+func newTestClient(t *testing.T, options tokenizer.Option, slots chan struct{}) TokenizerClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	RegisterTokenizerServer(server, NewServer(options, nil, slots))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	dial := func(context.Context, string) (net.Conn, error) { return listener.Dial() }
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+	return NewTokenizerClient(conn)
+}
+
+// tokenize streams the documents and collects the token values of each
+func tokenize(t *testing.T, client TokenizerClient, options []string, documents ...string) ([][]string, error) {
+	stream, err := client.Tokenize(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for i, text := range documents {
+			request := &TokenizeRequest{Text: text}
+
+			if i == 0 {
+				request.Options = options
+			}
+
+			if stream.Send(request) != nil {
+				break
+			}
+		}
+
+		stream.CloseSend()
+	}()
+
+	var results [][]string
+	var values []string
+
+	for {
+		batch, err := stream.Recv()
+
+		if err == io.EOF {
+			return results, nil
+		} else if err != nil {
+			return results, err
+		} else if int(batch.Document) != len(results) {
+			t.Errorf("expected document %d, got %d", len(results), batch.Document)
+		}
+
+		for _, token := range batch.Tokens {
+			values = append(values, token.Value)
+		}
+
+		if batch.Last {
+			results = append(results, values)
+			values = nil
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	client := newTestClient(t, tokenizer.Entities, nil)
+	results, err := tokenize(t, client, nil, "Fish &amp; chips.", "", "Two words")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"Fish & chips .", "", "Two words"}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d documents, got %d", len(expected), len(results))
+	}
+
+	for i, values := range results {
+		if strings.Join(values, " ") != expected[i] {
+			t.Errorf("document %d: expected %q, got %q", i, expected[i], values)
+		}
+	}
+}
+
+func TestTokenizeOptions(t *testing.T) {
+	client := newTestClient(t, tokenizer.Entities, nil)
+	results, err := tokenize(t, client, []string{"Lowercase"}, "Fish &amp; Chips")
+
+	if err != nil {
+		t.Fatal(err)
+	} else if strings.Join(results[0], " ") != "fish & amp ; chips" {
+		t.Errorf("unexpected tokens %q", results[0])
+	}
+
+	if _, err = tokenize(t, client, []string{"nope"}, "text"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an InvalidArgument error, got %v", err)
+	}
+}
+
+func TestTokenizeBatches(t *testing.T) {
+	client := newTestClient(t, tokenizer.NoOptions, nil)
+	document := strings.Repeat("word ", 2*batchSize+1)
+	stream, err := client.Tokenize(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	} else if err = stream.Send(&TokenizeRequest{Text: document}); err != nil {
+		t.Fatal(err)
+	}
+
+	stream.CloseSend()
+	var sizes []int
+	var last []bool
+
+	for batch, err := stream.Recv(); err != io.EOF; batch, err = stream.Recv() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sizes = append(sizes, len(batch.Tokens))
+		last = append(last, batch.Last)
+	}
+
+	if len(sizes) != 3 || sizes[0] != batchSize || sizes[2] != 1 || !last[2] || last[0] {
+		t.Errorf("unexpected batches of %v tokens (last: %v)", sizes, last)
+	}
+}
+
+func TestTokenizeSlots(t *testing.T) {
+	slots := make(chan struct{}, 1)
+	client := newTestClient(t, tokenizer.NoOptions, slots)
+	slots <- struct{}{} // (taken, as by an HTTP request)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	stream, err := client.Tokenize(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	} else if err = stream.Send(&TokenizeRequest{Text: "waiting"}); err != nil {
+		t.Fatal(err)
+	} else if _, err = stream.Recv(); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected a DeadlineExceeded error, got %v", err)
+	}
+
+	<-slots
+	results, err := tokenize(t, client, nil, "free now")
+
+	if err != nil {
+		t.Fatal(err)
+	} else if strings.Join(results[0], " ") != "free now" || len(slots) != 0 {
+		t.Errorf("unexpected tokens %q (or slots %d)", results[0], len(slots))
+	}
+}
+
+func TestTokenizeDehyphenate(t *testing.T) {
+	client := newTestClient(t, tokenizer.Dehyphenate, nil)
+	results, err := tokenize(t, client, nil, "the infor-", "mation age", "end-")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"the information", "age", "end -"}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d documents, got %q", len(expected), results)
+	}
+
+	for i, values := range results {
+		if strings.Join(values, " ") != expected[i] {
+			t.Errorf("document %d: expected %q, got %q", i, expected[i], values)
+		}
+	}
+}
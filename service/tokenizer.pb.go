@@ -0,0 +1,327 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: tokenizer.proto
+
+package service
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A document to tokenize.
+type TokenizeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the text of the document
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// the names of the options to use instead of the server's ("entities",
+	// "lowercase", ...); only read from the first request of a stream
+	Options       []string `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenizeRequest) Reset() {
+	*x = TokenizeRequest{}
+	mi := &file_tokenizer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenizeRequest) ProtoMessage() {}
+
+func (x *TokenizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tokenizer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenizeRequest.ProtoReflect.Descriptor instead.
+func (*TokenizeRequest) Descriptor() ([]byte, []int) {
+	return file_tokenizer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TokenizeRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TokenizeRequest) GetOptions() []string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// A batch of the tokens of a document.
+type TokenBatch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the index of the document in the stream (from 0)
+	Document uint64 `protobuf:"varint,1,opt,name=document,proto3" json:"document,omitempty"`
+	// the tokens, in order
+	Tokens []*Token `protobuf:"bytes,2,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	// true for the last batch of the document
+	Last          bool `protobuf:"varint,3,opt,name=last,proto3" json:"last,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenBatch) Reset() {
+	*x = TokenBatch{}
+	mi := &file_tokenizer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenBatch) ProtoMessage() {}
+
+func (x *TokenBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_tokenizer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenBatch.ProtoReflect.Descriptor instead.
+func (*TokenBatch) Descriptor() ([]byte, []int) {
+	return file_tokenizer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TokenBatch) GetDocument() uint64 {
+	if x != nil {
+		return x.Document
+	}
+	return 0
+}
+
+func (x *TokenBatch) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *TokenBatch) GetLast() bool {
+	if x != nil {
+		return x.Last
+	}
+	return false
+}
+
+// A token, as produced by the lexer.
+type Token struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the class name of the token ("Word", "Number", "Symbol", ...)
+	Class string `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"`
+	// the (normalized) value of the token
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// the token as found in the document
+	Raw string `protobuf:"bytes,3,opt,name=raw,proto3" json:"raw,omitempty"`
+	// the byte offsets of the raw token in the document
+	Start int32 `protobuf:"varint,4,opt,name=start,proto3" json:"start,omitempty"`
+	End   int32 `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	// the word shape and features (with the Shapes option)
+	Shape    string `protobuf:"bytes,6,opt,name=shape,proto3" json:"shape,omitempty"`
+	Features string `protobuf:"bytes,7,opt,name=features,proto3" json:"features,omitempty"`
+	// the dominant Unicode script (with the Scripts option)
+	Script        string `protobuf:"bytes,8,opt,name=script,proto3" json:"script,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_tokenizer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_tokenizer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_tokenizer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Token) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *Token) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Token) GetRaw() string {
+	if x != nil {
+		return x.Raw
+	}
+	return ""
+}
+
+func (x *Token) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Token) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Token) GetShape() string {
+	if x != nil {
+		return x.Shape
+	}
+	return ""
+}
+
+func (x *Token) GetFeatures() string {
+	if x != nil {
+		return x.Features
+	}
+	return ""
+}
+
+func (x *Token) GetScript() string {
+	if x != nil {
+		return x.Script
+	}
+	return ""
+}
+
+var File_tokenizer_proto protoreflect.FileDescriptor
+
+const file_tokenizer_proto_rawDesc = "" +
+	"\n" +
+	"\x0ftokenizer.proto\x12\ftokenizer.v1\"?\n" +
+	"\x0fTokenizeRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x18\n" +
+	"\aoptions\x18\x02 \x03(\tR\aoptions\"i\n" +
+	"\n" +
+	"TokenBatch\x12\x1a\n" +
+	"\bdocument\x18\x01 \x01(\x04R\bdocument\x12+\n" +
+	"\x06tokens\x18\x02 \x03(\v2\x13.tokenizer.v1.TokenR\x06tokens\x12\x12\n" +
+	"\x04last\x18\x03 \x01(\bR\x04last\"\xb7\x01\n" +
+	"\x05Token\x12\x14\n" +
+	"\x05class\x18\x01 \x01(\tR\x05class\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x10\n" +
+	"\x03raw\x18\x03 \x01(\tR\x03raw\x12\x14\n" +
+	"\x05start\x18\x04 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\x12\x14\n" +
+	"\x05shape\x18\x06 \x01(\tR\x05shape\x12\x1a\n" +
+	"\bfeatures\x18\a \x01(\tR\bfeatures\x12\x16\n" +
+	"\x06script\x18\b \x01(\tR\x06script2T\n" +
+	"\tTokenizer\x12G\n" +
+	"\bTokenize\x12\x1d.tokenizer.v1.TokenizeRequest\x1a\x18.tokenizer.v1.TokenBatch(\x010\x01B\"Z github.com/fnl/tokenizer/serviceb\x06proto3"
+
+var (
+	file_tokenizer_proto_rawDescOnce sync.Once
+	file_tokenizer_proto_rawDescData []byte
+)
+
+func file_tokenizer_proto_rawDescGZIP() []byte {
+	file_tokenizer_proto_rawDescOnce.Do(func() {
+		file_tokenizer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tokenizer_proto_rawDesc), len(file_tokenizer_proto_rawDesc)))
+	})
+	return file_tokenizer_proto_rawDescData
+}
+
+var file_tokenizer_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_tokenizer_proto_goTypes = []any{
+	(*TokenizeRequest)(nil), // 0: tokenizer.v1.TokenizeRequest
+	(*TokenBatch)(nil),      // 1: tokenizer.v1.TokenBatch
+	(*Token)(nil),           // 2: tokenizer.v1.Token
+}
+var file_tokenizer_proto_depIdxs = []int32{
+	2, // 0: tokenizer.v1.TokenBatch.tokens:type_name -> tokenizer.v1.Token
+	0, // 1: tokenizer.v1.Tokenizer.Tokenize:input_type -> tokenizer.v1.TokenizeRequest
+	1, // 2: tokenizer.v1.Tokenizer.Tokenize:output_type -> tokenizer.v1.TokenBatch
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_tokenizer_proto_init() }
+func file_tokenizer_proto_init() {
+	if File_tokenizer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tokenizer_proto_rawDesc), len(file_tokenizer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tokenizer_proto_goTypes,
+		DependencyIndexes: file_tokenizer_proto_depIdxs,
+		MessageInfos:      file_tokenizer_proto_msgTypes,
+	}.Build()
+	File_tokenizer_proto = out.File
+	file_tokenizer_proto_goTypes = nil
+	file_tokenizer_proto_depIdxs = nil
+}
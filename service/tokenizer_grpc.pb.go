@@ -0,0 +1,133 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: tokenizer.proto
+
+package service
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Tokenizer_Tokenize_FullMethodName = "/tokenizer.v1.Tokenizer/Tokenize"
+)
+
+// TokenizerClient is the client API for Tokenizer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// The Tokenizer service lexes streams of documents.
+type TokenizerClient interface {
+	// Tokenize lexes the documents streamed in, with the options of the first
+	// request, and streams their tokens back in batches, in order.
+	// As with Lex, a stream's documents are the lexer's inputs, so the options
+	// that continue across inputs (like Markdown or Dehyphenate) continue from
+	// one document to the next, but each document's tokens are in its own
+	// batches; a document ending in a word broken by a hyphen (with Dehyphenate)
+	// is only answered once the next document or the end of the stream arrives.
+	Tokenize(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[TokenizeRequest, TokenBatch], error)
+}
+
+type tokenizerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTokenizerClient(cc grpc.ClientConnInterface) TokenizerClient {
+	return &tokenizerClient{cc}
+}
+
+func (c *tokenizerClient) Tokenize(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[TokenizeRequest, TokenBatch], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Tokenizer_ServiceDesc.Streams[0], Tokenizer_Tokenize_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TokenizeRequest, TokenBatch]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Tokenizer_TokenizeClient = grpc.BidiStreamingClient[TokenizeRequest, TokenBatch]
+
+// TokenizerServer is the server API for Tokenizer service.
+// All implementations must embed UnimplementedTokenizerServer
+// for forward compatibility.
+//
+// The Tokenizer service lexes streams of documents.
+type TokenizerServer interface {
+	// Tokenize lexes the documents streamed in, with the options of the first
+	// request, and streams their tokens back in batches, in order.
+	// As with Lex, a stream's documents are the lexer's inputs, so the options
+	// that continue across inputs (like Markdown or Dehyphenate) continue from
+	// one document to the next, but each document's tokens are in its own
+	// batches; a document ending in a word broken by a hyphen (with Dehyphenate)
+	// is only answered once the next document or the end of the stream arrives.
+	Tokenize(grpc.BidiStreamingServer[TokenizeRequest, TokenBatch]) error
+	mustEmbedUnimplementedTokenizerServer()
+}
+
+// UnimplementedTokenizerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTokenizerServer struct{}
+
+func (UnimplementedTokenizerServer) Tokenize(grpc.BidiStreamingServer[TokenizeRequest, TokenBatch]) error {
+	return status.Error(codes.Unimplemented, "method Tokenize not implemented")
+}
+func (UnimplementedTokenizerServer) mustEmbedUnimplementedTokenizerServer() {}
+func (UnimplementedTokenizerServer) testEmbeddedByValue()                   {}
+
+// UnsafeTokenizerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TokenizerServer will
+// result in compilation errors.
+type UnsafeTokenizerServer interface {
+	mustEmbedUnimplementedTokenizerServer()
+}
+
+func RegisterTokenizerServer(s grpc.ServiceRegistrar, srv TokenizerServer) {
+	// If the following call panics, it indicates UnimplementedTokenizerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Tokenizer_ServiceDesc, srv)
+}
+
+func _Tokenizer_Tokenize_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TokenizerServer).Tokenize(&grpc.GenericServerStream[TokenizeRequest, TokenBatch]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Tokenizer_TokenizeServer = grpc.BidiStreamingServer[TokenizeRequest, TokenBatch]
+
+// Tokenizer_ServiceDesc is the grpc.ServiceDesc for Tokenizer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Tokenizer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tokenizer.v1.Tokenizer",
+	HandlerType: (*TokenizerServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Tokenize",
+			Handler:       _Tokenizer_Tokenize_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "tokenizer.proto",
+}
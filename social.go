@@ -0,0 +1,114 @@
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// regular expressions to match social media tokens at the start of a string
+var (
+	hashtag = regexp.MustCompile(`^#[\pL\pN_]*\pL[\pL\pN_]*`)
+	mention = regexp.MustCompile(`^@[\pL\pN_]+`)
+	cashtag = regexp.MustCompile(`^\$[A-Za-z]{1,6}(?:[._][A-Za-z]{1,2})?`)
+	// western emoticons, optionally reversed, and some eastern ones
+	emoticon = regexp.MustCompile(`^(?:` +
+		`[<>]?[:;=][-o*']?[)\](\[dDpP/\\:}{@|3]|` +
+		`[)\](\[/\\}{@|][-o*']?[:;=][<>]?|` +
+		`</?3|\^_*\^|-_+-|>_+<|;_;)`)
+)
+
+// runes that may follow an emoticon
+const emoticonEnds string = ".,!?)\"'"
+
+// acceptSocial consumes a hashtag, mention, cashtag, or emoticon
+// starting with the last scanned symbol,
+// returning the class of the token;
+// otherwise, returns SymbolToken and consumes nothing more
+func (l *lexer) acceptSocial() TokenClass {
+	text := l.buffer[l.start:]
+	class := SymbolToken
+	var m string
+
+	switch r := l.lastRune(); {
+	case r == '#' && !l.followsWord():
+		if m = hashtag.FindString(text); m != "" {
+			class = HashtagToken
+		}
+	case r == '@' && !l.followsWord():
+		if m = mention.FindString(text); m != "" {
+			class = MentionToken
+		}
+	case r == '$' && !l.followsWord():
+		if m = cashtag.FindString(text); m != "" && !isLetterOrDigit(firstRune(text[len(m):])) {
+			class = CashtagToken
+		}
+	}
+
+	if class == SymbolToken && l.followsSpace() {
+		if m = emoticon.FindString(text); m != "" {
+			if next := firstRune(text[len(m):]); next == utf8.RuneError ||
+				unicode.IsSpace(next) || strings.ContainsRune(emoticonEnds, next) {
+				class = EmoticonToken
+			}
+		}
+	}
+
+	if class != SymbolToken {
+		l.pos = l.start + len(m)
+		l.width = 0
+	}
+
+	return class
+}
+
+// followsWord is true if the current token follows a letter or digit
+func (l *lexer) followsWord() bool {
+	r, _ := utf8.DecodeLastRuneInString(l.buffer[:l.start])
+	return isLetterOrDigit(r)
+}
+
+// followsSpace is true if the current token starts the input or follows a space
+func (l *lexer) followsSpace() bool {
+	r, _ := utf8.DecodeLastRuneInString(l.buffer[:l.start])
+	return l.start == 0 || unicode.IsSpace(r)
+}
+
+// firstRune returns the first rune of a string
+// (or RuneError if it is empty)
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// shortenElongations shortens runs of more than three identical letters
+// to three ("sooooo" -> "sooo")
+func shortenElongations(word string) string {
+	var b strings.Builder
+	var last rune
+	repeat, from := 0, 0 // from: the start of the part not yet copied
+
+	for i, r := range word {
+		if r == last && unicode.IsLetter(r) {
+			repeat++
+		} else {
+			last, repeat = r, 1
+		}
+
+		if repeat == 4 {
+			b.WriteString(word[from:i])
+		}
+
+		if repeat >= 4 {
+			from = i + utf8.RuneLen(r) // drop this rune
+		}
+	}
+
+	if from == 0 {
+		return word // nothing to shorten
+	}
+
+	b.WriteString(word[from:])
+	return b.String()
+}
@@ -13,6 +13,15 @@ const (
 	NumberToken                      // numeric (digits) token (with ','* dec. and '.'? f.p. sep.)
 	SpaceToken                       // whitespaces, tabs, etc. (category Z)
 	SymbolToken                      // anything else; non-whitespace, single rune
+	TagToken                         // HTML/XML tags and comments (Tags option)
+	CodeToken                        // code spans and blocks (Markdown option)
+	URLToken                         // link targets (Markdown option)
+	HashtagToken                     // "#hashtags" (Social option)
+	MentionToken                     // "@mentions" (Social option)
+	CashtagToken                     // "$CASHTAGS" (Social option)
+	EmoticonToken                    // ":-)", "<3", "^_^", etc. (Social option)
+	InvalidToken                     // invalid UTF-8 and control characters (EmitInvalid option)
+	ErrorToken                       // the reason an input failed (FailInvalid option)
 )
 
 var className = []string{
@@ -22,12 +31,29 @@ var className = []string{
 	"Number",
 	"Space",
 	"Symbol",
+	"Tag",
+	"Code",
+	"URL",
+	"Hashtag",
+	"Mention",
+	"Cashtag",
+	"Emoticon",
+	"Invalid",
+	"Error",
 }
 
 // a token, as produced by the lexer
 type Token struct {
 	Class TokenClass // the class of the token
-	Value string     // the value of the token
+	Value string     // the (normalized) value of the token
+	Raw   string     // the token as found in the input (before any normalization)
+	Start int        // the byte offset of the raw token in the input
+	End   int        // the byte offset after the raw token in the input
+	// set by the lexer's Shapes option:
+	Shape    string  // the word shape of the token ("Xxxx", "dd-dd")
+	Features Feature // the orthographic features of the token
+	// set by the lexer's Scripts option:
+	Script string // the dominant Unicode script of the token ("Latin")
 	//PoS   string     // the token's part-of-speech (not set by the lexer)
 }
 
@@ -74,3 +100,48 @@ func (t Token) IsSpace() bool {
 func (t Token) IsSymbol() bool {
 	return t.Class == SymbolToken
 }
+
+// true if the token is an HTML/XML tag
+func (t Token) IsTag() bool {
+	return t.Class == TagToken
+}
+
+// true if the token is a code span or block
+func (t Token) IsCode() bool {
+	return t.Class == CodeToken
+}
+
+// true if the token is a URL
+func (t Token) IsURL() bool {
+	return t.Class == URLToken
+}
+
+// true if the token is a hashtag
+func (t Token) IsHashtag() bool {
+	return t.Class == HashtagToken
+}
+
+// true if the token is a mention
+func (t Token) IsMention() bool {
+	return t.Class == MentionToken
+}
+
+// true if the token is a cashtag
+func (t Token) IsCashtag() bool {
+	return t.Class == CashtagToken
+}
+
+// true if the token is an emoticon
+func (t Token) IsEmoticon() bool {
+	return t.Class == EmoticonToken
+}
+
+// true if the token holds invalid UTF-8 or control characters
+func (t Token) IsInvalid() bool {
+	return t.Class == InvalidToken
+}
+
+// true if the token reports why the input failed
+func (t Token) IsError() bool {
+	return t.Class == ErrorToken
+}
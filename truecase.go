@@ -0,0 +1,141 @@
+package tokenizer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A Truecaser maps lowercased words to their most frequent casing.
+type Truecaser struct {
+	forms map[string]string // lowercased words to their true case
+}
+
+// runes that end a sentence (for truecasing)
+const sentenceTerminals string = ".!?"
+
+// TrainTruecaser learns the most frequent casing of every word
+// from a stream of tokens, until the token channel is closed.
+//
+// Words at the start of a sentence and words in all-caps headlines
+// are ignored, because their casing is not informative.
+func TrainTruecaser(tokens chan Token) *Truecaser {
+	counts := make(map[string]map[string]int)
+	var words []string
+	var text strings.Builder
+	start := true
+
+	for token := range tokens {
+		switch {
+		case token.IsEnd():
+			if !isHeadline(text.String()) {
+				for _, w := range words {
+					lower := strings.ToLower(w)
+
+					if counts[lower] == nil {
+						counts[lower] = make(map[string]int)
+					}
+
+					counts[lower][w]++
+				}
+			}
+
+			words = words[:0]
+			text.Reset()
+			start = true
+		case token.IsWord():
+			if !start {
+				words = append(words, token.Value)
+			}
+
+			text.WriteString(token.Value)
+			start = false
+		case token.IsNumber():
+			start = false
+		case token.IsSymbol():
+			start = start || strings.Contains(sentenceTerminals, token.Value)
+		}
+	}
+
+	t := &Truecaser{forms: make(map[string]string, len(counts))}
+
+	for lower, forms := range counts {
+		best, max := "", 0
+
+		for form, n := range forms {
+			if n > max || n == max && form < best {
+				best, max = form, n
+			}
+		}
+
+		t.forms[lower] = best
+	}
+
+	return t
+}
+
+// ReadTruecaser loads a truecasing model written by WriteTo:
+// one word per line, in its true case.
+func ReadTruecaser(r io.Reader) (*Truecaser, error) {
+	t := &Truecaser{forms: make(map[string]string)}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if form := strings.TrimSpace(scanner.Text()); form != "" {
+			t.forms[strings.ToLower(form)] = form
+		}
+	}
+
+	return t, scanner.Err()
+}
+
+// WriteTo writes the truecasing model, one word per line (sorted).
+func (t *Truecaser) WriteTo(w io.Writer) (int64, error) {
+	forms := make([]string, 0, len(t.forms))
+	var total int64
+
+	for _, form := range t.forms {
+		forms = append(forms, form)
+	}
+
+	sort.Strings(forms)
+
+	for _, form := range forms {
+		n, err := fmt.Fprintln(w, form)
+		total += int64(n)
+
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// Truecase returns the true case of a word,
+// or the word itself if the model does not know it.
+func (t *Truecaser) Truecase(word string) string {
+	if form, ok := t.forms[strings.ToLower(word)]; ok {
+		return form
+	}
+
+	return word
+}
+
+// true if the text has several uppercase but no lowercase letters
+func isHeadline(text string) bool {
+	upper := 0
+
+	for _, r := range text {
+		if unicode.IsLower(r) {
+			return false
+		} else if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	return upper > 1
+}
@@ -0,0 +1,81 @@
+package tokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var truecaseCorpus = []string{
+	"The new iPhone was shown by Apple.",
+	"Yesterday, NASA said the launch was delayed.",
+	"Users of the iPhone buy apps; Apple sells them.",
+	"APPLE AND NASA LAUNCH THE IPHONE",
+	"Apple stock rose. The NASA budget did not.",
+}
+
+func trainTestTruecaser() *Truecaser {
+	in := make(chan string, len(truecaseCorpus))
+
+	for _, line := range truecaseCorpus {
+		in <- line
+	}
+
+	close(in)
+	return TrainTruecaser(Lex(in, 100, NoOptions))
+}
+
+func TestTrainTruecaser(t *testing.T) {
+	model := trainTestTruecaser()
+	expected := map[string]string{
+		"the": "the", "THE": "the", "apple": "Apple", "IPHONE": "iPhone",
+		"nasa": "NASA", "Users": "Users", "unknown": "unknown",
+	}
+
+	for word, form := range expected {
+		if model.Truecase(word) != form {
+			t.Errorf("expected %q for %q, got %q", form, word, model.Truecase(word))
+		}
+	}
+}
+
+func TestTruecaserReadWrite(t *testing.T) {
+	var buffer bytes.Buffer
+	model := trainTestTruecaser()
+
+	if _, err := model.WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buffer.String(), "\niPhone\n") {
+		t.Errorf("model lacks iPhone:\n%s", buffer.String())
+	}
+
+	loaded, err := ReadTruecaser(&buffer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(loaded.forms) != fmt.Sprint(model.forms) {
+		t.Errorf("expected %v, got %v", model.forms, loaded.forms)
+	}
+}
+
+var truecaseCases = []lexerModeTestCase{
+	{"sentence start", "The iPhone. Apple sells it.",
+		[]string{"the", "iPhone", ".", "Apple", "sells", "it", ".", "|"}},
+	{"headline", "NASA LAUNCHES THE IPHONE",
+		[]string{"NASA", "LAUNCHES", "the", "iPhone", "|"}},
+	{"elsewhere", "Users buy NASA and APPLE stuff",
+		[]string{"Users", "buy", "NASA", "and", "APPLE", "stuff", "|"}},
+}
+
+func TestTruecaseOption(t *testing.T) {
+	resources := &Resources{Truecaser: trainTestTruecaser()}
+
+	for _, test := range truecaseCases {
+		linesLexerTest(t, Truecase|Lowercase, resources, test.description, []string{test.line}, test.expected)
+	}
+}
@@ -0,0 +1,212 @@
+package tokenizer
+
+//go:generate go run gen_wordbreak.go
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// a Word_Break property value (UAX #29)
+type wordBreak uint8
+
+// all Word_Break property values (Format is merged into Extend)
+const (
+	wbOther wordBreak = iota
+	wbCR
+	wbLF
+	wbNewline
+	wbExtend
+	wbZWJ
+	wbRegionalIndicator
+	wbKatakana
+	wbHebrewLetter
+	wbALetter
+	wbSingleQuote
+	wbDoubleQuote
+	wbMidNumLet
+	wbMidLetter
+	wbMidNum
+	wbNumeric
+	wbExtendNumLet
+	wbWSegSpace
+)
+
+// a range of runes with the same Word_Break property value
+type wordBreakRange struct {
+	lo, hi rune
+	value  wordBreak
+}
+
+// wordBreakOf returns the Word_Break property value of a rune
+func wordBreakOf(r rune) wordBreak {
+	i := sort.Search(len(wordBreakRanges), func(i int) bool {
+		return wordBreakRanges[i].hi >= r
+	})
+
+	if i < len(wordBreakRanges) && wordBreakRanges[i].lo <= r {
+		return wordBreakRanges[i].value
+	}
+
+	return wbOther
+}
+
+// true if the rune has the Extended_Pictographic property
+func isExtendedPictographic(r rune) bool {
+	i := sort.Search(len(extendedPictographicRanges), func(i int) bool {
+		return extendedPictographicRanges[i][1] >= r
+	})
+
+	return i < len(extendedPictographicRanges) && extendedPictographicRanges[i][0] <= r
+}
+
+// true if the property is ALetter or Hebrew_Letter
+func isAHLetter(wb wordBreak) bool {
+	return wb == wbALetter || wb == wbHebrewLetter
+}
+
+// true if the property is MidNumLet or Single_Quote
+func isMidNumLetQ(wb wordBreak) bool {
+	return wb == wbMidNumLet || wb == wbSingleQuote
+}
+
+// true if the property is ignored after other characters (WB4)
+func isIgnored(wb wordBreak) bool {
+	return wb == wbExtend || wb == wbZWJ
+}
+
+// true if the property breaks lines
+func isNewline(wb wordBreak) bool {
+	return wb == wbCR || wb == wbLF || wb == wbNewline
+}
+
+// nextWordBreak returns the property of the first rune in the text
+// that is not ignored by rule WB4 (or wbOther at the end of the text)
+func nextWordBreak(text string) wordBreak {
+	for _, r := range text {
+		if wb := wordBreakOf(r); !isIgnored(wb) {
+			return wb
+		}
+	}
+
+	return wbOther
+}
+
+// wordBoundary returns the length of the first word segment of a text
+// according to the Unicode word boundary rules (UAX #29, WB1-WB999)
+func wordBoundary(text string) int {
+	if text == "" {
+		return 0
+	}
+
+	r, pos := utf8.DecodeRuneInString(text)
+	raw := wordBreakOf(r)          // the property of the last rune
+	prev, prevprev := raw, wbOther // the last two properties not ignored by WB4
+	regional := 0                  // the number of consecutive regional indicators
+
+	if raw == wbRegionalIndicator {
+		regional = 1
+	}
+
+	for pos < len(text) {
+		r, w := utf8.DecodeRuneInString(text[pos:])
+		wb := wordBreakOf(r)
+
+		switch {
+		case raw == wbCR && wb == wbLF: // WB3
+		case isNewline(raw) || isNewline(wb): // WB3a, WB3b
+			return pos
+		case raw == wbZWJ && isExtendedPictographic(r): // WB3c
+		case raw == wbWSegSpace && wb == wbWSegSpace: // WB3d
+		case isIgnored(wb): // WB4
+			raw = wb
+			pos += w
+			continue
+		case isAHLetter(prev) && isAHLetter(wb): // WB5
+		case isAHLetter(prev) && (wb == wbMidLetter || isMidNumLetQ(wb)) &&
+			isAHLetter(nextWordBreak(text[pos+w:])): // WB6
+		case isAHLetter(prevprev) && (prev == wbMidLetter || isMidNumLetQ(prev)) &&
+			isAHLetter(wb): // WB7
+		case prev == wbHebrewLetter && wb == wbSingleQuote: // WB7a
+		case prev == wbHebrewLetter && wb == wbDoubleQuote &&
+			nextWordBreak(text[pos+w:]) == wbHebrewLetter: // WB7b
+		case prevprev == wbHebrewLetter && prev == wbDoubleQuote &&
+			wb == wbHebrewLetter: // WB7c
+		case prev == wbNumeric && wb == wbNumeric: // WB8
+		case isAHLetter(prev) && wb == wbNumeric: // WB9
+		case prev == wbNumeric && isAHLetter(wb): // WB10
+		case prevprev == wbNumeric && (prev == wbMidNum || isMidNumLetQ(prev)) &&
+			wb == wbNumeric: // WB11
+		case prev == wbNumeric && (wb == wbMidNum || isMidNumLetQ(wb)) &&
+			nextWordBreak(text[pos+w:]) == wbNumeric: // WB12
+		case prev == wbKatakana && wb == wbKatakana: // WB13
+		case (isAHLetter(prev) || prev == wbNumeric || prev == wbKatakana ||
+			prev == wbExtendNumLet) && wb == wbExtendNumLet: // WB13a
+		case prev == wbExtendNumLet &&
+			(isAHLetter(wb) || wb == wbNumeric || wb == wbKatakana): // WB13b
+		case prev == wbRegionalIndicator && wb == wbRegionalIndicator &&
+			regional%2 == 1: // WB15, WB16
+		default: // WB999
+			return pos
+		}
+
+		if wb == wbRegionalIndicator {
+			regional++
+		} else {
+			regional = 0
+		}
+
+		raw, prev, prevprev = wb, wb, prev
+		pos += w
+	}
+
+	return pos
+}
+
+// lexUAX29 segments the buffer at the Unicode word boundaries (UAX #29)
+// and assigns each segment a token class:
+// segments with letters are words, segments of digits (and separators)
+// are numbers, spaces and linebreaks are emitted as requested,
+// control characters are ignored, and anything else is a symbol
+func lexUAX29(l *lexer) stateFn {
+	for l.pos < len(l.buffer) {
+		l.pos += wordBoundary(l.buffer[l.pos:])
+		segment := l.buffer[l.start:l.pos]
+		letters, digits, spaces, breaks, others := 0, 0, 0, 0, 0
+
+		for _, r := range segment {
+			switch {
+			case unicode.IsLetter(r):
+				letters++
+			case unicode.IsDigit(r):
+				digits++
+			case isSpace(r) || wordBreakOf(r) == wbWSegSpace:
+				spaces++
+			case isEOL(r):
+				breaks++
+			case unicode.IsControl(r):
+			default:
+				others++
+			}
+		}
+
+		switch {
+		case letters > 0:
+			l.emit(WordToken)
+		case digits > 0:
+			l.emit(NumberToken)
+		case others > 0:
+			l.emit(SymbolToken)
+		case spaces > 0 && l.emitsSpaces():
+			l.emit(SpaceToken)
+		case breaks > 0 && l.emitsLinebreaks():
+			l.emit(LinebreakToken)
+		default:
+			l.ignore()
+		}
+	}
+
+	l.width = 0
+	return lexEnd
+}